@@ -80,5 +80,7 @@ func initRouter() *gin.Engine {
 
 	router.GET(IndexEndpoint, index)
 
+	initMiningRoutes(router)
+
 	return router
 }
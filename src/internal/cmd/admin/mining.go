@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+
+	dns_client "github.com/antavelos/blockchain/src/internal/pkg/clients/dns"
+	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	MiningEndpoint                  = "/mining"
+	MiningStartEndpoint             = "/mining/start"
+	MiningStopEndpoint              = "/mining/stop"
+	NodeMiningStartEndpoint         = "/mining/:name/start"
+	NodeMiningStopEndpoint          = "/mining/:name/stop"
+	NodeMiningWorkersEndpoint       = "/mining/:name/workers"
+	NodeMiningRewardAddressEndpoint = "/mining/:name/reward-address"
+)
+
+// NodeMiningStatus is the mining status of a node of the cluster or the
+// error that prevented retrieving it.
+type NodeMiningStatus struct {
+	Node   nd.Node       `json:"node"`
+	Status mining.Status `json:"status"`
+	Error  string        `json:"error,omitempty"`
+}
+
+func newNodeMiningStatus(node nd.Node, status mining.Status, err error) NodeMiningStatus {
+	nodeStatus := NodeMiningStatus{Node: node, Status: status}
+	if err != nil {
+		nodeStatus.Error = err.Error()
+	}
+
+	return nodeStatus
+}
+
+func getNodes() ([]nd.Node, error) {
+	nodes, err := dns_client.GetDNSNodes(getDNSHost())
+	if err != nil {
+		return nil, utils.GenericError{Msg: "nodes not available"}
+	}
+
+	return nodes, nil
+}
+
+func findNode(c *gin.Context) (nd.Node, bool) {
+	nodes, err := getNodes()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nd.Node{}, false
+	}
+
+	for _, node := range nodes {
+		if node.Name == c.Param("name") {
+			return node, true
+		}
+	}
+
+	c.IndentedJSON(http.StatusNotFound, gin.H{"error": "node not found"})
+	return nd.Node{}, false
+}
+
+// forEachNode applies the mining request to every node of the cluster.
+func forEachNode(c *gin.Context, request func(nd.Node) (mining.Status, error)) {
+	nodes, err := getNodes()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statuses := utils.Map(nodes, func(node nd.Node) NodeMiningStatus {
+		status, err := request(node)
+		return newNodeMiningStatus(node, status, err)
+	})
+
+	c.IndentedJSON(http.StatusOK, statuses)
+}
+
+func forNode(c *gin.Context, request func(nd.Node) (mining.Status, error)) {
+	node, ok := findNode(c)
+	if !ok {
+		return
+	}
+
+	status, err := request(node)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, newNodeMiningStatus(node, status, nil))
+}
+
+func getMiningStatuses(c *gin.Context) {
+	forEachNode(c, node_client.GetMiningStatus)
+}
+
+func startMining(c *gin.Context) {
+	forEachNode(c, node_client.StartMining)
+}
+
+func stopMining(c *gin.Context) {
+	forEachNode(c, node_client.StopMining)
+}
+
+func startNodeMining(c *gin.Context) {
+	forNode(c, node_client.StartMining)
+}
+
+func stopNodeMining(c *gin.Context) {
+	forNode(c, node_client.StopMining)
+}
+
+func setNodeMiningWorkers(c *gin.Context) {
+	var input struct {
+		Workers int `json:"workers"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	forNode(c, func(node nd.Node) (mining.Status, error) {
+		return node_client.SetMiningWorkers(node, input.Workers)
+	})
+}
+
+func setNodeMiningRewardAddress(c *gin.Context) {
+	var input struct {
+		Address string `json:"address"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	forNode(c, func(node nd.Node) (mining.Status, error) {
+		return node_client.SetMiningRewardAddress(node, input.Address)
+	})
+}
+
+func initMiningRoutes(router *gin.Engine) {
+	router.GET(MiningEndpoint, getMiningStatuses)
+	router.POST(MiningStartEndpoint, startMining)
+	router.POST(MiningStopEndpoint, stopMining)
+	router.POST(NodeMiningStartEndpoint, startNodeMining)
+	router.POST(NodeMiningStopEndpoint, stopNodeMining)
+	router.POST(NodeMiningWorkersEndpoint, setNodeMiningWorkers)
+	router.POST(NodeMiningRewardAddressEndpoint, setNodeMiningRewardAddress)
+}
@@ -1,46 +1,98 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/finality"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/miner"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/pool"
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
 	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
+	sub "github.com/antavelos/blockchain/src/internal/pkg/models/submission"
 	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
 	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
 	"github.com/antavelos/blockchain/src/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const transactionsEndpoint = "/transactions"
+const transactionEndpoint = "/transactions/:id"
+const transactionWaitEndpoint = "/transactions/:id/wait"
+const transactionProofEndpoint = "/transactions/:id/proof"
+const headersEndpoint = "/headers"
+const stateProofEndpoint = "/state/:address/proof"
+const blocksEndpoint = "/blocks"
+const snapshotsEndpoint = "/snapshots"
+const snapshotEndpoint = "/snapshots/:height"
 const sharedTransactionsEndpoint = "/shared-transactions"
 const sharedBlocksEndpoint = "/shared-blocks"
 const pingEndpoint = "/ping"
 const blockchainEndpoint = "/blockchain"
+const txPoolIdsEndpoint = "/tx-pool/ids"
+const txPoolTxsEndpoint = "/tx-pool/txs"
+const submissionsEndpoint = "/submissions"
+const submissionEndpoint = "/submissions/:id"
+
+const defaultWaitTimeoutInSec = 60
+const maxWaitTimeoutInSec = 300
+const waitPollInterval = 500 * time.Millisecond
 
 type RouteHandler struct {
-	Bus   *eventbus.Bus
-	Repos *rep.Repos
+	Bus      *eventbus.Bus
+	Repos    *rep.Repos
+	Miner    *miner.Miner
+	Pool     *pool.Server
+	Finality *finality.Monitor
+	Clock    *nettime.Clock
 }
 
-func NewRouteHandler(bus *eventbus.Bus, repos *rep.Repos) *RouteHandler {
-	return &RouteHandler{Bus: bus, Repos: repos}
+func NewRouteHandler(bus *eventbus.Bus, repos *rep.Repos, miner *miner.Miner, finality *finality.Monitor, clock *nettime.Clock) *RouteHandler {
+	return &RouteHandler{Bus: bus, Repos: repos, Miner: miner, Finality: finality, Clock: clock}
 }
 
 func (h *RouteHandler) addSharedBlock(c *gin.Context) {
-	var block bc.Block
+	data, err := io.ReadAll(c.Request.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.IndentedJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	// oversized blocks are refused before being decoded
+	if err := h.Repos.BlockchainRepo.Limits.CheckEncodedBlock(data); err != nil {
+		c.IndentedJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := c.BindJSON(&block); err != nil {
+	var block bc.Block
+	if err := json.Unmarshal(data, &block); err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
 		return
 	}
 
-	err := h.Repos.BlockchainRepo.AddBlock(block)
+	err = h.Repos.BlockchainRepo.AddBlock(block)
+	if events.ReportFinalityViolation(h.Bus, err) {
+		c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.Bus.Handle(eventbus.DataEvent{Ev: events.TipChangedEvent, Data: block})
 
 	c.IndentedJSON(http.StatusCreated, block)
 }
@@ -53,11 +105,18 @@ func (h *RouteHandler) addSharedTx(c *gin.Context) {
 		return
 	}
 
+	// rebroadcasted transactions may already be known
+	if tx.Id != "" && h.Repos.BlockchainRepo.HasTx(tx) {
+		c.IndentedJSON(http.StatusOK, tx)
+		return
+	}
+
 	tx, err := h.Repos.BlockchainRepo.AddTx(tx)
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.Bus.Handle(eventbus.DataEvent{Ev: events.TxPoolChangedEvent, Data: tx})
 
 	c.IndentedJSON(http.StatusCreated, tx)
 }
@@ -75,16 +134,136 @@ func (h *RouteHandler) addTx(c *gin.Context) {
 		return
 	}
 
-	tx, err := h.Repos.BlockchainRepo.AddTx(tx)
+	// the id is assigned upfront so that rejected transactions can be queried
+	if tx.Id == "" {
+		tx.Id = uuid.NewString()
+	}
+
+	err := tx.Validate()
+	if err == nil {
+		var added bc.Transaction
+		if added, err = h.Repos.BlockchainRepo.AddTx(tx); err == nil {
+			tx = added
+		}
+	}
 	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.rejectTx(tx, err)
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "id": tx.Id})
 		return
 	}
 	h.Bus.Handle(eventbus.DataEvent{Ev: events.TransactionReceivedEvent, Data: tx})
+	h.Bus.Handle(eventbus.DataEvent{Ev: events.TxPoolChangedEvent, Data: tx})
 
 	c.IndentedJSON(http.StatusCreated, tx)
 }
 
+func (h *RouteHandler) rejectTx(tx bc.Transaction, reason error) {
+	submission := sub.NewRejectedSubmission(tx, time.Now().UnixMilli(), reason.Error())
+	if err := h.Repos.SubmissionRepo.AddSubmission(submission); err != nil {
+		utils.LogError("Failed to track the rejected transaction", err.Error())
+	}
+}
+
+func (h *RouteHandler) getReceipt(txId string) (bc.Receipt, error) {
+	if receipt, ok := h.Repos.BlockchainRepo.GetReceipt(txId); ok {
+		return receipt, nil
+	}
+
+	submission, err := h.Repos.SubmissionRepo.GetSubmission(txId)
+	if err != nil {
+		return bc.Receipt{}, utils.GenericError{Msg: "transaction not found"}
+	}
+
+	if receipt, ok := submission.Receipt(); ok {
+		return receipt, nil
+	}
+
+	return bc.Receipt{}, utils.GenericError{Msg: "transaction not found"}
+}
+
+func (h *RouteHandler) getTx(c *gin.Context) {
+	receipt, err := h.getReceipt(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, receipt)
+}
+
+// waitTx blocks until the transaction reaches the requested number of
+// confirmations, gets rejected or dropped, or the timeout expires.
+func (h *RouteHandler) waitTx(c *gin.Context) {
+	confirmations, err := strconv.ParseInt(c.DefaultQuery("confirmations", "1"), 10, 64)
+	if err != nil || confirmations < 1 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid confirmations"})
+		return
+	}
+
+	timeoutInSec, err := strconv.Atoi(c.DefaultQuery("timeout", strconv.Itoa(defaultWaitTimeoutInSec)))
+	if err != nil || timeoutInSec < 1 || timeoutInSec > maxWaitTimeoutInSec {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid timeout"})
+		return
+	}
+
+	deadline := time.Now().Add(time.Duration(timeoutInSec) * time.Second)
+	for {
+		receipt, err := h.getReceipt(c.Param("id"))
+		if err != nil {
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		if receipt.IsFinal(confirmations) {
+			c.IndentedJSON(http.StatusOK, receipt)
+			return
+		}
+
+		if time.Now().After(deadline) {
+			c.IndentedJSON(http.StatusRequestTimeout, receipt)
+			return
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+func (h *RouteHandler) getTxProof(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	index, ok := h.loadIndex(c)
+	if !ok {
+		return
+	}
+
+	txId := c.Param("id")
+
+	_, block, found := findIndexedTx(blockchain, index, txId)
+	if !found && isPrunedTx(blockchain, index, txId) {
+		respondPruned(c)
+		return
+	}
+	if !found {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "transaction not confirmed"})
+		return
+	}
+
+	proof, err := bc.NewInclusionProof(block, txId)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, proof)
+}
+
 func (h *RouteHandler) getBlockchain(c *gin.Context) {
 	blockchain, err := h.Repos.BlockchainRepo.GetBlockchain()
 	if err != nil {
@@ -92,9 +271,154 @@ func (h *RouteHandler) getBlockchain(c *gin.Context) {
 		return
 	}
 
+	if blockchain.IsPruned() {
+		respondPruned(c)
+		return
+	}
+
 	c.IndentedJSON(http.StatusOK, *blockchain)
 }
 
+func (h *RouteHandler) getTxPoolIds(c *gin.Context) {
+	ids, err := h.Repos.BlockchainRepo.GetTxPoolIds()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "transaction pool currently not available"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, ids)
+}
+
+func (h *RouteHandler) getPoolTxs(c *gin.Context) {
+	var ids []string
+	if err := c.BindJSON(&ids); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	txs, err := h.Repos.BlockchainRepo.GetPoolTxs(ids)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "transaction pool currently not available"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, txs)
+}
+
+func (h *RouteHandler) getSubmissions(c *gin.Context) {
+	submissions, err := h.Repos.SubmissionRepo.GetSubmissions()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "submissions currently not available"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, submissions)
+}
+
+func (h *RouteHandler) getSubmission(c *gin.Context) {
+	submission, err := h.Repos.SubmissionRepo.GetSubmission(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, submission)
+}
+
+func (h *RouteHandler) getHeaders(c *gin.Context) {
+	from, err := strconv.ParseInt(c.DefaultQuery("from", "1"), 10, 64)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, blockchain.Headers(from))
+}
+
+func (h *RouteHandler) getStateProof(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	height := blockchain.State.Height
+	if c.Query("height") != "" {
+		var err error
+		height, err = strconv.ParseInt(c.Query("height"), 10, 64)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid height"})
+			return
+		}
+	}
+
+	state, found := blockchain.GetState(height)
+	if !found {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "state not available at the given height"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, state.Prove(c.Param("address")))
+}
+
+func (h *RouteHandler) getBlocks(c *gin.Context) {
+	from, err := strconv.ParseInt(c.DefaultQuery("from", "1"), 10, 64)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	if from <= blockchain.BaseState.Height {
+		respondPruned(c)
+		return
+	}
+
+	blocks := utils.Filter(blockchain.Blocks, func(block bc.Block) bool {
+		return block.Idx >= from
+	})
+
+	c.IndentedJSON(http.StatusOK, blocks)
+}
+
+func (h *RouteHandler) getSnapshots(c *gin.Context) {
+	snapshots, err := h.Repos.SnapshotRepo.GetSnapshots()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "snapshots currently not available"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, snapshots)
+}
+
+func (h *RouteHandler) getSnapshot(c *gin.Context) {
+	height, err := strconv.ParseInt(c.Param("height"), 10, 64)
+	if err != nil || height <= 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid height"})
+		return
+	}
+
+	snapshot, err := h.Repos.SnapshotRepo.GetSnapshot(height)
+	if err == rep.ErrSnapshotNotFound {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, snapshot)
+}
+
 func (h *RouteHandler) ping(c *gin.Context) {
 	var node nd.Node
 	if err := c.BindJSON(&node); err != nil {
@@ -103,6 +427,11 @@ func (h *RouteHandler) ping(c *gin.Context) {
 	}
 	utils.LogInfo("Ping from", node.GetHost())
 
+	if node.Time > 0 {
+		h.addTimeSample(c.RemoteIP(), time.UnixMilli(node.Time))
+		node.Time = 0
+	}
+
 	err := h.Repos.NodeRepo.AddNode(node)
 	if err != nil {
 		utils.LogError(err.Error())
@@ -120,16 +449,69 @@ func (h *RouteHandler) ping(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, nodes)
 }
 
+// limitBody rejects the requests whose body exceeds maxBytes, without reading
+// past the limit.
+func limitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// addTimeSample takes the time reported by a known peer into account, keyed by
+// the address the request came from so that every peer counts once.
+func (h *RouteHandler) addTimeSample(ip string, peerTime time.Time) {
+	nodes, err := h.Repos.NodeRepo.GetNodes()
+	if err != nil {
+		return
+	}
+
+	for _, node := range nodes {
+		if node.IP == ip {
+			h.Clock.AddSample(ip, peerTime)
+			return
+		}
+	}
+}
+
 func (routeHandler *RouteHandler) InitRouter() *gin.Engine {
 	router := gin.Default()
 
 	router.SetTrustedProxies([]string{"localhost", "127.0.0.1"})
+	// shared blocks are the largest request bodies
+	router.Use(limitBody(int64(routeHandler.Repos.BlockchainRepo.Limits.MaxBlockBytes)))
 
 	router.POST(transactionsEndpoint, routeHandler.addTx)
+	router.GET(transactionEndpoint, routeHandler.getTx)
+	router.GET(transactionWaitEndpoint, routeHandler.waitTx)
+	router.GET(transactionProofEndpoint, routeHandler.getTxProof)
 	router.POST(sharedTransactionsEndpoint, routeHandler.addSharedTx)
 	router.POST(sharedBlocksEndpoint, routeHandler.addSharedBlock)
 	router.POST(pingEndpoint, routeHandler.ping)
 	router.GET(blockchainEndpoint, routeHandler.getBlockchain)
+	router.GET(headersEndpoint, routeHandler.getHeaders)
+	router.GET(stateProofEndpoint, routeHandler.getStateProof)
+	router.GET(blocksEndpoint, routeHandler.getBlocks)
+	router.GET(snapshotsEndpoint, routeHandler.getSnapshots)
+	router.GET(snapshotEndpoint, routeHandler.getSnapshot)
+	router.GET(txPoolIdsEndpoint, routeHandler.getTxPoolIds)
+	router.POST(txPoolTxsEndpoint, routeHandler.getPoolTxs)
+	router.GET(submissionsEndpoint, routeHandler.getSubmissions)
+	router.GET(submissionEndpoint, routeHandler.getSubmission)
+	router.GET(finalityEndpoint, routeHandler.getFinality)
+
+	routeHandler.initExplorerRoutes(router)
+	routeHandler.initMiningRoutes(router)
 
 	return router
 }
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	ex "github.com/antavelos/blockchain/src/internal/pkg/models/explorer"
+	idx "github.com/antavelos/blockchain/src/internal/pkg/models/index"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const v1Group = "/v1"
+const latestBlockEndpoint = "/blocks/latest"
+const blockByHeightEndpoint = "/blocks/height/:height"
+const blockByHeightTxsEndpoint = "/blocks/height/:height/txs"
+const blockByHashEndpoint = "/blocks/hash/:hash"
+const blockByHashTxsEndpoint = "/blocks/hash/:hash/txs"
+const txEndpoint = "/txs/:id"
+const addressBalanceEndpoint = "/addresses/:address/balance"
+const addressTxsEndpoint = "/addresses/:address/txs"
+
+func (h *RouteHandler) loadBlockchain(c *gin.Context) (*bc.Blockchain, bool) {
+	blockchain, err := h.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "blockchain currently not available"})
+		return nil, false
+	}
+
+	return blockchain, true
+}
+
+func (h *RouteHandler) loadIndex(c *gin.Context) (*idx.Index, bool) {
+	index, err := h.Repos.IndexRepo.GetIndex()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "indexes currently not available"})
+		return nil, false
+	}
+
+	return index, true
+}
+
+func (h *RouteHandler) findBlock(c *gin.Context) (bc.Block, bool) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return bc.Block{}, false
+	}
+
+	var height int64
+	var found bool
+
+	if hash := c.Param("hash"); hash != "" {
+		index, ok := h.loadIndex(c)
+		if !ok {
+			return bc.Block{}, false
+		}
+		height, found = index.GetHeight(hash)
+	} else {
+		var err error
+		height, err = strconv.ParseInt(c.Param("height"), 10, 64)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid height"})
+			return bc.Block{}, false
+		}
+		found = true
+	}
+
+	var block bc.Block
+	if found {
+		block, found = blockchain.GetBlockByIdx(height)
+	}
+
+	if !found {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "block not found"})
+		return bc.Block{}, false
+	}
+
+	return block, true
+}
+
+// respondPruned refuses requests for the transactions a pruned node no longer
+// keeps so that peers fetch them from archive nodes instead.
+func respondPruned(c *gin.Context) {
+	c.IndentedJSON(http.StatusGone, gin.H{"error": "pruned data is not served by this node"})
+}
+
+func paginate[T any](c *gin.Context, items []T) {
+	limit, err := ex.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := ex.Paginate(items, c.Query("cursor"), limit)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+func (h *RouteHandler) getLatestBlock(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, ex.NewBlock(blockchain.LastBlock()))
+}
+
+func (h *RouteHandler) getBlock(c *gin.Context) {
+	block, ok := h.findBlock(c)
+	if !ok {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, ex.NewBlock(block))
+}
+
+func (h *RouteHandler) getBlockTxs(c *gin.Context) {
+	block, ok := h.findBlock(c)
+	if !ok {
+		return
+	}
+
+	if block.Pruned {
+		respondPruned(c)
+		return
+	}
+
+	txs := utils.Map(block.Txs, func(tx bc.Transaction) ex.Transaction {
+		return ex.NewTransaction(tx, block)
+	})
+
+	paginate(c, txs)
+}
+
+func (h *RouteHandler) getExplorerTx(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	index, ok := h.loadIndex(c)
+	if !ok {
+		return
+	}
+
+	txId := c.Param("id")
+
+	if tx, block, found := findIndexedTx(blockchain, index, txId); found {
+		c.IndentedJSON(http.StatusOK, ex.NewTransaction(tx, block))
+		return
+	}
+
+	if tx, found := blockchain.GetPendingTx(txId); found {
+		c.IndentedJSON(http.StatusOK, ex.NewTransaction(tx, bc.Block{}))
+		return
+	}
+
+	if isPrunedTx(blockchain, index, txId) {
+		respondPruned(c)
+		return
+	}
+
+	c.IndentedJSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+}
+
+func findIndexedTx(blockchain *bc.Blockchain, index *idx.Index, txId string) (bc.Transaction, bc.Block, bool) {
+	location, found := index.GetTxLocation(txId)
+	if !found {
+		return bc.Transaction{}, bc.Block{}, false
+	}
+
+	block, found := blockchain.GetBlockByIdx(location.BlockIdx)
+	if !found || location.Position >= len(block.Txs) || block.Txs[location.Position].Id != txId {
+		return bc.Transaction{}, bc.Block{}, false
+	}
+
+	return block.Txs[location.Position], block, true
+}
+
+// isPrunedTx reports whether the transaction is confirmed in a block whose
+// transactions got pruned.
+func isPrunedTx(blockchain *bc.Blockchain, index *idx.Index, txId string) bool {
+	location, found := index.GetTxLocation(txId)
+	if !found {
+		return false
+	}
+
+	block, found := blockchain.GetBlockByIdx(location.BlockIdx)
+
+	return found && block.Pruned
+}
+
+func (h *RouteHandler) getAddressBalance(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	address := c.Param("address")
+
+	// the balance is read from the state since the address index does not cover
+	// the blocks before a snapshot
+	c.IndentedJSON(http.StatusOK, ex.Balance{
+		Address:   address,
+		Confirmed: blockchain.GetConfirmedBalance(address),
+		Pending:   blockchain.GetPendingBalance(address),
+		NextNonce: blockchain.NextNonce(address),
+	})
+}
+
+func (h *RouteHandler) getAddressTxs(c *gin.Context) {
+	blockchain, ok := h.loadBlockchain(c)
+	if !ok {
+		return
+	}
+
+	index, ok := h.loadIndex(c)
+	if !ok {
+		return
+	}
+
+	txIds := index.GetAddressTxIds(c.Param("address"))
+
+	txs := make([]ex.Transaction, 0, len(txIds))
+	for _, txId := range txIds {
+		if tx, block, found := findIndexedTx(blockchain, index, txId); found {
+			txs = append(txs, ex.NewTransaction(tx, block))
+		}
+	}
+
+	paginate(c, txs)
+}
+
+func (h *RouteHandler) initExplorerRoutes(router *gin.Engine) {
+	v1 := router.Group(v1Group)
+
+	v1.GET(latestBlockEndpoint, h.getLatestBlock)
+	v1.GET(blockByHeightEndpoint, h.getBlock)
+	v1.GET(blockByHeightTxsEndpoint, h.getBlockTxs)
+	v1.GET(blockByHashEndpoint, h.getBlock)
+	v1.GET(blockByHashTxsEndpoint, h.getBlockTxs)
+	v1.GET(txEndpoint, h.getExplorerTx)
+	v1.GET(addressBalanceEndpoint, h.getAddressBalance)
+	v1.GET(addressTxsEndpoint, h.getAddressTxs)
+}
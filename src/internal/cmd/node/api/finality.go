@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const finalityEndpoint = "/finality"
+
+func (h *RouteHandler) getFinality(c *gin.Context) {
+	status, err := h.Finality.Status()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, status)
+}
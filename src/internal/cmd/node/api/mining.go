@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/antavelos/blockchain/src/internal/cmd/node/miner"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+
+	"github.com/gin-gonic/gin"
+)
+
+const miningEndpoint = "/mining"
+const miningStartEndpoint = "/mining/start"
+const miningStopEndpoint = "/mining/stop"
+const miningWorkersEndpoint = "/mining/workers"
+const miningRewardAddressEndpoint = "/mining/reward-address"
+const blockTemplateEndpoint = "/mining/template"
+const submitBlockEndpoint = "/mining/submit"
+const poolEndpoint = "/pool"
+
+type workersInput struct {
+	Workers int `json:"workers"`
+}
+
+type rewardAddressInput struct {
+	Address string `json:"address"`
+}
+
+func (h *RouteHandler) getMiningStatus(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, h.Miner.Status())
+}
+
+func (h *RouteHandler) startMining(c *gin.Context) {
+	h.Miner.Start()
+
+	c.IndentedJSON(http.StatusOK, h.Miner.Status())
+}
+
+func (h *RouteHandler) stopMining(c *gin.Context) {
+	h.Miner.Stop()
+
+	c.IndentedJSON(http.StatusOK, h.Miner.Status())
+}
+
+func (h *RouteHandler) setMiningWorkers(c *gin.Context) {
+	var input workersInput
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	if err := h.Miner.SetWorkers(input.Workers); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, h.Miner.Status())
+}
+
+func (h *RouteHandler) setMiningRewardAddress(c *gin.Context) {
+	var input rewardAddressInput
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	if err := h.Miner.SetRewardAddress(input.Address); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, h.Miner.Status())
+}
+
+func (h *RouteHandler) getPoolAccounting(c *gin.Context) {
+	if h.Pool == nil {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "pool mode not enabled"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, h.Pool.Accounting())
+}
+
+func (h *RouteHandler) initMiningRoutes(router *gin.Engine) {
+	router.GET(miningEndpoint, h.getMiningStatus)
+	router.POST(miningStartEndpoint, h.startMining)
+	router.POST(miningStopEndpoint, h.stopMining)
+	router.POST(miningWorkersEndpoint, h.setMiningWorkers)
+	router.POST(miningRewardAddressEndpoint, h.setMiningRewardAddress)
+	router.GET(blockTemplateEndpoint, h.getBlockTemplate)
+	router.POST(submitBlockEndpoint, h.submitBlock)
+	router.GET(poolEndpoint, h.getPoolAccounting)
+}
+
+func (h *RouteHandler) getBlockTemplate(c *gin.Context) {
+	template, err := h.Miner.NewBlockTemplate(c.Query("rewardAddress"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, template)
+}
+
+func (h *RouteHandler) submitBlock(c *gin.Context) {
+	var submission mining.BlockSubmission
+	if err := c.BindJSON(&submission); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	block, err := h.Miner.SubmitBlock(submission)
+	switch {
+	case err == miner.ErrTemplateNotFound:
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == miner.ErrInvalidProofOfWork:
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.IndentedJSON(http.StatusCreated, block)
+	}
+}
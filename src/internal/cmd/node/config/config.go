@@ -1,6 +1,10 @@
 package config
 
 import (
+	"time"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
 	cfg "github.com/antavelos/blockchain/src/pkg/config"
 	"github.com/antavelos/blockchain/src/pkg/utils"
 )
@@ -18,14 +22,61 @@ var envVars []string = []string{
 	"TXS_PER_BLOCK",
 	"REWARD_AMOUNT",
 	"NODE_NAME",
+	"SUBMISSIONS_FILENAME",
+	"REBROADCAST_INTERVAL_IN_SEC",
+	"REBROADCAST_MAX_INTERVAL_IN_SEC",
+	"TX_EXPIRY_IN_SEC",
+	"INDEX_FILENAME",
+	"LIGHT_FILENAME",
+	"LIGHT_SYNC_INTERVAL_IN_SEC",
+	"SNAPSHOTS_DIR",
+	"SNAPSHOT_INTERVAL",
+	"SNAPSHOTS_TO_KEEP",
+	"SNAPSHOT_SYNC",
+	"PRUNE_DEPTH",
+	"MINING_WORKERS",
+	"MIN_BLOCK_INTERVAL_IN_SEC",
+	"MAX_BLOCK_INTERVAL_IN_SEC",
+	"MINE_EMPTY_BLOCKS",
+	"POOL_PORT",
+	"POOL_SHARE_DIFFICULTY",
+	"BLOCK_ASSEMBLY_POLICY",
+	"MAX_BLOCK_BYTES",
+	"REWARD_ADDRESSES",
+	"GENESIS_FILENAME",
+	"FINALITY_DEPTH",
+	"MEDIAN_TIME_SPAN",
+	"MAX_FUTURE_DRIFT_IN_SEC",
+	"MAX_CLOCK_ADJUSTMENT_IN_SEC",
 }
 
 type Config struct {
-	c                       cfg.Config
-	CoinBaseSenderAddress   string  //= "0"
-	DefaultTxsPerBlock      int     //= 10
-	DefaultMiningDifficulty int     //= 2
-	DefaultRewardAmount     float64 //= 1.0
+	c                           cfg.Config
+	CoinBaseSenderAddress       string  //= "0"
+	DefaultTxsPerBlock          int     //= 10
+	DefaultRewardAmount         float64 //= 1.0
+	RebroadcastIntervalInSec    int     //= 30
+	RebroadcastMaxIntervalInSec int     //= 600
+	TxExpiryInSec               int     //= 3600
+	LightSyncIntervalInSec      int     //= 10
+	SnapshotInterval            int     //= 100
+	SnapshotsToKeep             int     //= 3
+	SnapshotSync                bool    //= false
+	PruneDepth                  int     //= 0
+	MiningWorkers               int     //= 1
+	MinBlockIntervalInSec       int     //= 1
+	MaxBlockIntervalInSec       int     //= 60
+	MineEmptyBlocks             bool    //= false
+	PoolShareDifficulty         int     //= 1
+	MaxBlockBytes               int     //= 1000000
+	MedianTimeSpan              int     //= 11
+	MaxFutureDriftInSec         int     //= 15
+	MaxClockAdjustmentInSec     int     //= 70
+	AssemblyPolicy              bc.AssemblyPolicy
+	RewardSplits                []mining.RewardSplit
+	Genesis                     bc.Genesis
+	Consensus                   bc.Consensus
+	Finality                    bc.Finality
 }
 
 func NewConfig() (*Config, error) {
@@ -34,15 +85,94 @@ func NewConfig() (*Config, error) {
 		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
 	}
 
+	assemblyPolicy, err := bc.NewAssemblyPolicy(config["BLOCK_ASSEMBLY_POLICY"])
+	if err != nil {
+		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
+	}
+
+	rewardSplits, err := mining.ParseRewardSplits(config["REWARD_ADDRESSES"])
+	if err != nil {
+		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
+	}
+
+	genesis, err := loadGenesis(config)
+	if err != nil {
+		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
+	}
+
+	consensus, err := bc.NewConsensus(genesis.Consensus)
+	if err != nil {
+		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
+	}
+
+	finality := bc.Finality{
+		Checkpoints: genesis.Checkpoints,
+		Depth:       int64(config.GetInteger("FINALITY_DEPTH", 0)),
+	}
+	if err := finality.Validate(); err != nil {
+		return nil, utils.GenericError{Msg: "Configuration error", Extra: err}
+	}
+
 	return &Config{
-		c:                       config,
-		CoinBaseSenderAddress:   "0",
-		DefaultTxsPerBlock:      config.GetInteger("TXS_PER_BLOCK", 10),
-		DefaultMiningDifficulty: config.GetInteger("MINING_DIFFICULTY", 2),
-		DefaultRewardAmount:     config.GetFloat("REWARD_AMOUNT", 1.0),
+		c:                           config,
+		CoinBaseSenderAddress:       "0",
+		DefaultTxsPerBlock:          config.GetInteger("TXS_PER_BLOCK", 10),
+		DefaultRewardAmount:         config.GetFloat("REWARD_AMOUNT", 1.0),
+		RebroadcastIntervalInSec:    config.GetInteger("REBROADCAST_INTERVAL_IN_SEC", 30),
+		RebroadcastMaxIntervalInSec: config.GetInteger("REBROADCAST_MAX_INTERVAL_IN_SEC", 600),
+		TxExpiryInSec:               config.GetInteger("TX_EXPIRY_IN_SEC", 3600),
+		LightSyncIntervalInSec:      config.GetInteger("LIGHT_SYNC_INTERVAL_IN_SEC", 10),
+		SnapshotInterval:            config.GetInteger("SNAPSHOT_INTERVAL", 100),
+		SnapshotsToKeep:             config.GetInteger("SNAPSHOTS_TO_KEEP", 3),
+		SnapshotSync:                config.GetBool("SNAPSHOT_SYNC", false),
+		PruneDepth:                  config.GetInteger("PRUNE_DEPTH", 0),
+		MiningWorkers:               config.GetInteger("MINING_WORKERS", 1),
+		MinBlockIntervalInSec:       config.GetInteger("MIN_BLOCK_INTERVAL_IN_SEC", 1),
+		MaxBlockIntervalInSec:       config.GetInteger("MAX_BLOCK_INTERVAL_IN_SEC", 60),
+		MineEmptyBlocks:             config.GetBool("MINE_EMPTY_BLOCKS", false),
+		PoolShareDifficulty:         config.GetInteger("POOL_SHARE_DIFFICULTY", 1),
+		MaxBlockBytes:               config.GetInteger("MAX_BLOCK_BYTES", 1000000),
+		MedianTimeSpan:              config.GetInteger("MEDIAN_TIME_SPAN", 11),
+		MaxFutureDriftInSec:         config.GetInteger("MAX_FUTURE_DRIFT_IN_SEC", 15),
+		MaxClockAdjustmentInSec:     config.GetInteger("MAX_CLOCK_ADJUSTMENT_IN_SEC", 70),
+		AssemblyPolicy:              assemblyPolicy,
+		RewardSplits:                rewardSplits,
+		Genesis:                     genesis,
+		Consensus:                   consensus,
+		Finality:                    finality,
 	}, nil
 }
 
+// loadGenesis reads the genesis file or, when none is configured, falls back
+// to proof of work of the configured mining difficulty.
+func loadGenesis(config cfg.Config) (bc.Genesis, error) {
+	if config["GENESIS_FILENAME"] == "" {
+		return bc.DefaultGenesis(config.GetInteger("MINING_DIFFICULTY", 2)), nil
+	}
+
+	return bc.LoadGenesis(config["GENESIS_FILENAME"])
+}
+
+// BlockLimits are the limits blocks are assembled within, narrowed to the
+// consensus limits of the genesis.
+func (c *Config) BlockLimits() bc.BlockLimits {
+	return c.Genesis.Limits.Bound(bc.BlockLimits{MaxTxs: c.DefaultTxsPerBlock, MaxBytes: c.MaxBlockBytes})
+}
+
+// TimestampRules are the rules the blocks' timestamps are checked against.
+func (c *Config) TimestampRules() bc.TimestampRules {
+	return bc.TimestampRules{
+		MedianSpan:     c.MedianTimeSpan,
+		MaxFutureDrift: time.Duration(c.MaxFutureDriftInSec) * time.Second,
+	}
+}
+
+// IsPruned reports whether the node keeps the transactions of the last
+// PruneDepth blocks only.
+func (c *Config) IsPruned() bool {
+	return c.PruneDepth > 0
+}
+
 func (c *Config) Get(key string) string {
 	return c.c[key]
 }
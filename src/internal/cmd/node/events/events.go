@@ -7,7 +7,9 @@ import (
 const (
 	InitNodeEvent            eventbus.Event = "InitNodeEvent"
 	TransactionReceivedEvent eventbus.Event = "TransactionReceivedEvent"
-	BlockMinedEvent          eventbus.Event = "BlockMinedEvent"
 	BlockMiningFailedEvent   eventbus.Event = "BlockMiningFailedEvent"
 	ConnectionRefusedEvent   eventbus.Event = "ConnectionRefusedEvent"
+	TxPoolChangedEvent       eventbus.Event = "TxPoolChangedEvent"
+	TipChangedEvent          eventbus.Event = "TipChangedEvent"
+	FinalityViolationEvent   eventbus.Event = "FinalityViolationEvent"
 )
@@ -1,14 +1,19 @@
 package events
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
+	"time"
 
 	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/snapshots"
 	dns_client "github.com/antavelos/blockchain/src/internal/pkg/clients/dns"
 	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
 	wallet_client "github.com/antavelos/blockchain/src/internal/pkg/clients/wallet"
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
 	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
+	sub "github.com/antavelos/blockchain/src/internal/pkg/models/submission"
 	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
 	"github.com/antavelos/blockchain/src/pkg/eventbus"
 	"github.com/antavelos/blockchain/src/pkg/rest"
@@ -39,7 +44,10 @@ func (h EventHandler) getSelfNode() (nd.Node, error) {
 		return nd.Node{}, err
 	}
 
-	return nd.NewNode(h.Config.Get("NODE_NAME"), ip, h.Config.Get("PORT")), nil
+	node := nd.NewNode(h.Config.Get("NODE_NAME"), ip, h.Config.Get("PORT"))
+	node.Pruned = h.Config.IsPruned()
+
+	return node, nil
 }
 
 func (h EventHandler) HandleInitNode(event eventbus.DataEvent) {
@@ -49,6 +57,29 @@ func (h EventHandler) HandleInitNode(event eventbus.DataEvent) {
 	}
 }
 
+// syncFromSnapshot restores an empty local blockchain from the most recent
+// peer snapshot when SNAPSHOT_SYNC is enabled. It reports whether it did so.
+func (h EventHandler) syncFromSnapshot() bool {
+	if !h.Config.SnapshotSync {
+		return false
+	}
+
+	blockchain, err := h.Repos.BlockchainRepo.GetBlockchain()
+	if err == nil && len(blockchain.Blocks) > 0 {
+		return false
+	}
+
+	err = snapshots.NewSnapshotter(h.Config, h.Repos).SyncFromNodes()
+	if err != nil {
+		utils.LogError("Snapshot sync [FAIL]", err.Error())
+		return false
+	}
+
+	utils.LogInfo("Snapshot sync [OK]")
+
+	return true
+}
+
 func (h EventHandler) initNode() error {
 	err := h.introduceToDNS()
 	if err != nil {
@@ -65,9 +96,16 @@ func (h EventHandler) initNode() error {
 		utils.LogError("ping nodes error", err.Error())
 	}
 
-	h.resolveLongestBlockchain()
+	if !h.syncFromSnapshot() {
+		h.resolveLongestBlockchain()
+	}
+
+	err = h.syncMempool()
+	if err != nil {
+		utils.LogError("mempool sync error", err.Error())
+	}
 
-	if h.Repos.WalletRepo.IsEmpty() {
+	if h.Repos.WalletRepo.IsEmpty() && len(h.Config.RewardSplits) == 0 {
 		if err := h.createNewWallet(); err != nil {
 			return utils.GenericError{Msg: "failed to create new wallet", Extra: err}
 		}
@@ -115,6 +153,7 @@ func (h EventHandler) pingNodes() error {
 		return err
 	}
 
+	selfNode.Time = time.Now().UnixMilli()
 	responses := node_client.PingNodes(nodes, selfNode)
 
 	if responses.HasConnectionRefused() {
@@ -154,21 +193,52 @@ func (h EventHandler) getBlockchains(nodes []nd.Node) []*bc.Blockchain {
 	return blockchains
 }
 
+// ReportFinalityViolation fires a FinalityViolationEvent if the error is a
+// finality violation and reports whether it was one.
+func ReportFinalityViolation(bus *eventbus.Bus, err error) bool {
+	violation, ok := err.(bc.FinalityViolation)
+	if !ok {
+		return false
+	}
+
+	violation.Timestamp = time.Now().UnixMilli()
+	bus.Handle(eventbus.DataEvent{Ev: FinalityViolationEvent, Data: violation})
+
+	return true
+}
+
 func (h EventHandler) resolveLongestBlockchain() error {
 	nodes, err := h.Repos.NodeRepo.GetNodes()
 	if err != nil {
 		return err
 	}
 
+	// pruned nodes don't serve their full blockchain
+	nodes = utils.Filter(nodes, func(n nd.Node) bool {
+		return !n.Pruned
+	})
+
 	// TODO: include the below in a single lock
 
 	blockchains := h.getBlockchains(nodes)
 	utils.LogInfo("Retrieved blockchains", len(blockchains))
 
+	now := h.Repos.BlockchainRepo.Clock.Now()
+	blockchains = utils.Filter(blockchains, func(blockchain *bc.Blockchain) bool {
+		return blockchain.IsValid() &&
+			blockchain.VerifyConsensus(h.Config.Consensus) == nil &&
+			h.Config.TimestampRules().CheckChain(blockchain, now) == nil &&
+			h.Config.Genesis.Limits.CheckChain(blockchain) == nil
+	})
+
 	localBlockchain, _ := h.Repos.BlockchainRepo.GetBlockchain()
+
+	blockchains = utils.Filter(blockchains, func(blockchain *bc.Blockchain) bool {
+		return !ReportFinalityViolation(h.Bus, h.Config.Finality.CheckReorg(localBlockchain, blockchain))
+	})
 	blockchains = append(blockchains, localBlockchain)
 
-	maxLengthBlockchain := bc.GetMaxLengthBlockchain(blockchains)
+	maxLengthBlockchain := h.Config.Consensus.ForkChoice(blockchains)
 
 	if len(maxLengthBlockchain.Blocks) == 0 {
 		return nil
@@ -176,9 +246,85 @@ func (h EventHandler) resolveLongestBlockchain() error {
 
 	err = h.Repos.BlockchainRepo.UpdateBlockchain(maxLengthBlockchain)
 	if err != nil {
+		ReportFinalityViolation(h.Bus, err)
 		return utils.GenericError{Msg: "failed to update local blockchain", Extra: err}
 	}
 
+	if !bytes.Equal(maxLengthBlockchain.LastBlock().Hash(), localBlockchain.LastBlock().Hash()) {
+		h.Bus.Handle(eventbus.DataEvent{Ev: TipChangedEvent})
+	}
+
+	return nil
+}
+
+// syncMempool fetches from the known nodes the pending transactions that are
+// missing locally and adds those that are valid to the local pool.
+func (h EventHandler) syncMempool() error {
+	nodes, err := h.Repos.NodeRepo.GetNodes()
+	if err != nil {
+		return utils.GenericError{Msg: "couldn't load nodes", Extra: err}
+	}
+
+	blockchain, err := h.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return utils.GenericError{Msg: "blockchain currently not available", Extra: err}
+	}
+
+	known := make(map[string]bool)
+	var missingTxs []bc.Transaction
+
+	for _, node := range nodes {
+		ids, err := node_client.GetTxPoolIds(node)
+		if err != nil {
+			utils.LogError("Failed to retrieve transaction pool ids", node.GetHost(), err.Error())
+			continue
+		}
+
+		missingIds := utils.Filter(ids, func(id string) bool {
+			return !known[id] && !blockchain.HasTx(bc.Transaction{Id: id})
+		})
+		if len(missingIds) == 0 {
+			continue
+		}
+
+		txs, err := node_client.GetPoolTxs(node, missingIds)
+		if err != nil {
+			utils.LogError("Failed to retrieve pool transactions", node.GetHost(), err.Error())
+			continue
+		}
+
+		for _, tx := range txs {
+			known[tx.Id] = true
+		}
+		missingTxs = append(missingTxs, txs...)
+	}
+
+	// transactions are added in submission order so that a sender's earlier
+	// transactions are accounted for when validating the later ones
+	sort.Slice(missingTxs, func(i, j int) bool {
+		return missingTxs[i].Timestamp < missingTxs[j].Timestamp
+	})
+
+	added := 0
+	for _, tx := range missingTxs {
+		if err := tx.Validate(); err != nil {
+			utils.LogError("Invalid pool transaction", tx.Id, err.Error())
+			continue
+		}
+
+		if _, err := h.Repos.BlockchainRepo.AddTx(tx); err != nil {
+			utils.LogError("Failed to add pool transaction", tx.Id, err.Error())
+			continue
+		}
+		added++
+	}
+
+	utils.LogInfo("Synced pool transactions", added)
+
+	if added > 0 {
+		h.Bus.Handle(eventbus.DataEvent{Ev: TxPoolChangedEvent})
+	}
+
 	return nil
 }
 
@@ -194,27 +340,24 @@ func (h EventHandler) createNewWallet() error {
 func (h EventHandler) HandleTransactionReceivedEvent(event eventbus.DataEvent) {
 	tx := event.Data.(bc.Transaction)
 
+	now := time.Now().UnixMilli()
+	submission := sub.NewSubmission(tx, now)
+
 	nodes, _ := h.Repos.NodeRepo.GetNodes()
 	responses := node_client.ShareTx(nodes, tx)
 	if responses.ErrorsRatio() > 0 {
 		utils.LogError("Failed to share the transaction with some nodes", responses.Errors())
 	}
-}
-
-func (h EventHandler) HandleBlockMinedEvent(event eventbus.DataEvent) {
 
-	rewardTx, err := h.makeRewardTx()
-	if err != nil {
-		utils.LogError("Failed to create reward transaction", err.Error())
-		return
-	}
+	submission.Broadcasted(
+		now,
+		int64(h.Config.RebroadcastIntervalInSec)*1000,
+		int64(h.Config.RebroadcastMaxIntervalInSec)*1000,
+	)
 
-	err = h.reward(rewardTx)
-	if err != nil {
-		utils.LogError("Failed to reward self", err.Error())
+	if err := h.Repos.SubmissionRepo.AddSubmission(submission); err != nil {
+		utils.LogError("Failed to track the transaction submission", err.Error())
 	}
-
-	utils.LogInfo("Rewarded self with", h.Config.DefaultRewardAmount)
 }
 
 func (h EventHandler) HandleBlockMiningFailedEvent(event eventbus.DataEvent) {
@@ -225,47 +368,6 @@ func (h EventHandler) HandleBlockMiningFailedEvent(event eventbus.DataEvent) {
 	}
 }
 
-func (h EventHandler) makeRewardTx() (bc.Transaction, error) {
-	if h.Repos.WalletRepo.IsEmpty() {
-		return bc.Transaction{}, utils.GenericError{Msg: "node has no wallet"}
-	}
-
-	wallets, err := h.Repos.WalletRepo.GetWallets()
-	if err != nil {
-		return bc.Transaction{}, utils.GenericError{Msg: "failed to get wallets", Extra: err}
-	}
-
-	return bc.Transaction{
-		Body: bc.TransactionBody{
-			Sender:    h.Config.CoinBaseSenderAddress,
-			Recipient: wallets[0].AddressString(),
-			Amount:    h.Config.DefaultRewardAmount,
-		},
-	}, nil
-}
-
-func (h EventHandler) reward(tx bc.Transaction) error {
-
-	tx, err := h.Repos.BlockchainRepo.AddTx(tx)
-	if err != nil {
-		return utils.GenericError{Msg: "failed to add reward transaction", Extra: err}
-	}
-
-	nodes, _ := h.Repos.NodeRepo.GetNodes()
-	if err != nil {
-		return utils.GenericError{Msg: "failed to load nodes", Extra: err}
-	}
-
-	responses := node_client.ShareTx(nodes, tx)
-	if responses.ErrorsRatio() > 0 {
-		return utils.GenericError{
-			Msg: fmt.Sprintf("failed to share the transaction with other nodes: %v", responses.Errors()),
-		}
-	}
-
-	return nil
-}
-
 func (h EventHandler) HandleConnectionRefusedEvent(event eventbus.DataEvent) {
 	utils.LogInfo("Refresing DNS nodes")
 	err := h.refreshDNSNodes()
@@ -281,7 +383,6 @@ func NewEventBus(config *cfg.Config, repos *rep.Repos) *eventbus.Bus {
 
 	bus.RegisterEventHandler(InitNodeEvent, eh.HandleInitNode)
 	bus.RegisterEventHandler(TransactionReceivedEvent, eh.HandleTransactionReceivedEvent)
-	bus.RegisterEventHandler(BlockMinedEvent, eh.HandleBlockMinedEvent)
 	bus.RegisterEventHandler(BlockMiningFailedEvent, eh.HandleBlockMiningFailedEvent)
 	bus.RegisterEventHandler(ConnectionRefusedEvent, eh.HandleConnectionRefusedEvent)
 
@@ -0,0 +1,76 @@
+package finality
+
+import (
+	"sync"
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// maxViolations is the number of recent violations kept for the status.
+const maxViolations = 100
+
+// Monitor keeps track of the finality violations the node refused and
+// reports the finality of the local blockchain.
+type Monitor struct {
+	Config *cfg.Config
+	Repos  *rep.Repos
+
+	m          sync.Mutex
+	violations []bc.FinalityViolation
+}
+
+func NewMonitor(bus *eventbus.Bus, config *cfg.Config, repos *rep.Repos) *Monitor {
+	monitor := &Monitor{Config: config, Repos: repos, violations: []bc.FinalityViolation{}}
+	bus.RegisterEventHandler(events.FinalityViolationEvent, monitor.record)
+
+	return monitor
+}
+
+func (m *Monitor) record(event eventbus.DataEvent) {
+	violation, ok := event.Data.(bc.FinalityViolation)
+	if !ok {
+		return
+	}
+
+	utils.LogError("Finality violation", violation.Error())
+
+	if violation.Timestamp == 0 {
+		violation.Timestamp = time.Now().UnixMilli()
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.violations = append(m.violations, violation)
+	if len(m.violations) > maxViolations {
+		m.violations = m.violations[1:]
+	}
+}
+
+func (m *Monitor) Status() (bc.FinalityStatus, error) {
+	blockchain, err := m.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return bc.FinalityStatus{}, utils.GenericError{Msg: "blockchain currently not available"}
+	}
+
+	status := bc.FinalityStatus{
+		Finality:        m.Config.Finality,
+		FinalizedHeight: m.Config.Finality.FinalizedHeight(blockchain),
+	}
+
+	if block, found := blockchain.GetBlockByIdx(status.FinalizedHeight); found {
+		status.FinalizedHash = block.HashString()
+	}
+
+	m.m.Lock()
+	status.Violations = append([]bc.FinalityViolation{}, m.violations...)
+	m.m.Unlock()
+
+	return status, nil
+}
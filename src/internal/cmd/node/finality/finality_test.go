@@ -0,0 +1,25 @@
+package finality
+
+import (
+	"testing"
+	"time"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+)
+
+func TestMonitorStampsViolations(t *testing.T) {
+	monitor := Monitor{}
+	before := time.Now().UnixMilli()
+
+	monitor.record(eventbus.DataEvent{Data: bc.FinalityViolation{Height: 2, Reason: "checkpoint mismatch"}})
+	monitor.record(eventbus.DataEvent{Data: bc.FinalityViolation{Height: 3, Timestamp: 1}})
+
+	if stamped := monitor.violations[0].Timestamp; stamped < before || stamped > time.Now().UnixMilli() {
+		t.Errorf("Expected the violation to be stamped when recorded but got %v", stamped)
+	}
+
+	if stamped := monitor.violations[1].Timestamp; stamped != 1 {
+		t.Errorf("Expected the given timestamp to be kept but got %v", stamped)
+	}
+}
@@ -0,0 +1,150 @@
+package light
+
+import (
+	"net/http"
+	"strconv"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	ex "github.com/antavelos/blockchain/src/internal/pkg/models/explorer"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/light"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const headersEndpoint = "/headers"
+const watchedEndpoint = "/watched"
+const addressBalanceEndpoint = "/v1/addresses/:address/balance"
+const addressTxsEndpoint = "/v1/addresses/:address/txs"
+
+type RouteHandler struct {
+	Repos *rep.Repos
+}
+
+func NewRouteHandler(repos *rep.Repos) *RouteHandler {
+	return &RouteHandler{Repos: repos}
+}
+
+func (h *RouteHandler) loadChain(c *gin.Context) (light.Chain, bool) {
+	chain, err := h.Repos.LightRepo.GetChain()
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "headers currently not available"})
+		return light.Chain{}, false
+	}
+
+	return chain, true
+}
+
+func (h *RouteHandler) loadWatchedChain(c *gin.Context) (light.Chain, bool) {
+	chain, ok := h.loadChain(c)
+	if !ok {
+		return light.Chain{}, false
+	}
+
+	if !chain.IsWatched(c.Param("address")) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "address is not watched"})
+		return light.Chain{}, false
+	}
+
+	return chain, true
+}
+
+func (h *RouteHandler) getHeaders(c *gin.Context) {
+	from, err := strconv.ParseInt(c.DefaultQuery("from", "1"), 10, 64)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+
+	chain, ok := h.loadChain(c)
+	if !ok {
+		return
+	}
+
+	headers := utils.Filter(chain.Headers, func(header bc.BlockHeader) bool {
+		return header.Idx >= from
+	})
+
+	c.IndentedJSON(http.StatusOK, headers)
+}
+
+func (h *RouteHandler) getWatched(c *gin.Context) {
+	chain, ok := h.loadChain(c)
+	if !ok {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, chain.Watched)
+}
+
+func (h *RouteHandler) addWatched(c *gin.Context) {
+	var body struct {
+		Address string `json:"address"`
+	}
+	if err := c.BindJSON(&body); err != nil || body.Address == "" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	if err := h.Repos.LightRepo.Watch(body.Address); err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, body)
+}
+
+func (h *RouteHandler) getAddressBalance(c *gin.Context) {
+	chain, ok := h.loadWatchedChain(c)
+	if !ok {
+		return
+	}
+
+	address := c.Param("address")
+
+	c.IndentedJSON(http.StatusOK, ex.Balance{
+		Address:   address,
+		Confirmed: chain.Balance(address),
+	})
+}
+
+func (h *RouteHandler) getAddressTxs(c *gin.Context) {
+	chain, ok := h.loadWatchedChain(c)
+	if !ok {
+		return
+	}
+
+	txs := utils.Map(chain.History(c.Param("address")), func(vtx light.VerifiedTx) ex.Transaction {
+		header, _ := chain.GetHeader(vtx.BlockIdx)
+		return ex.NewTransaction(vtx.Tx, bc.Block{BlockHeader: header})
+	})
+
+	limit, err := ex.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := ex.Paginate(txs, c.Query("cursor"), limit)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+func (h *RouteHandler) InitRouter() *gin.Engine {
+	router := gin.Default()
+
+	router.SetTrustedProxies([]string{"localhost", "127.0.0.1"})
+
+	router.GET(headersEndpoint, h.getHeaders)
+	router.GET(watchedEndpoint, h.getWatched)
+	router.POST(watchedEndpoint, h.addWatched)
+	router.GET(addressBalanceEndpoint, h.getAddressBalance)
+	router.GET(addressTxsEndpoint, h.getAddressTxs)
+
+	return router
+}
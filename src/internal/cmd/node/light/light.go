@@ -0,0 +1,152 @@
+package light
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	dns_client "github.com/antavelos/blockchain/src/internal/pkg/clients/dns"
+	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
+	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// Client runs the node in light mode: it keeps only the block headers and
+// verifies the transactions of the watched addresses through Merkle proofs
+// fetched from full nodes.
+type Client struct {
+	Config *cfg.Config
+	Repos  *rep.Repos
+}
+
+func NewClient(config *cfg.Config, repos *rep.Repos) *Client {
+	return &Client{Config: config, Repos: repos}
+}
+
+func (lc *Client) getDNSHost() string {
+	return fmt.Sprintf("http://%v:%v", lc.Config.Get("DNS_HOST"), lc.Config.Get("DNS_PORT"))
+}
+
+func (lc *Client) getNodes() ([]nd.Node, error) {
+	nodes, err := dns_client.GetDNSNodes(lc.getDNSHost())
+	if err != nil {
+		return nil, utils.GenericError{Msg: "couldn't retrieve nodes from DNS", Extra: err}
+	}
+
+	return nodes, nil
+}
+
+// syncHeaders extends the local headers from each node, switching to the
+// node's headers chain when it has forked from the local one and is longer.
+// The genesis is the one pinned by the genesis checkpoint, if any, or else the
+// first one synced.
+func (lc *Client) syncHeaders(nodes []nd.Node) {
+	consensus := lc.Config.Consensus
+	genesisHash := lc.Config.Finality.Checkpoints[1]
+
+	for _, node := range nodes {
+		chain, err := lc.Repos.LightRepo.GetChain()
+		if err != nil {
+			utils.LogError("Light chain currently not available", err.Error())
+			return
+		}
+
+		from := chain.Height()
+		if from == 0 {
+			from = 1
+		}
+
+		headers, err := node_client.GetHeaders(node, from)
+		if err != nil {
+			utils.LogError("Failed to retrieve headers", node.GetHost(), err.Error())
+			continue
+		}
+
+		tip := chain.Tip()
+		if tip == nil {
+			err = lc.Repos.LightRepo.ExtendHeaders(headers, consensus, genesisHash)
+		} else if len(headers) > 0 && bytes.Equal(headers[0].Hash(), tip.Hash()) {
+			err = lc.Repos.LightRepo.ExtendHeaders(headers[1:], consensus, genesisHash)
+		} else {
+			headers, err = node_client.GetHeaders(node, 1)
+			if err == nil && len(headers) > len(chain.Headers) {
+				err = lc.Repos.LightRepo.ReplaceHeaders(headers, consensus, genesisHash)
+			}
+		}
+
+		if err != nil {
+			utils.LogError("Failed to sync headers", node.GetHost(), err.Error())
+		}
+	}
+}
+
+// syncWatchedTxs fetches the history of the watched addresses from the first
+// node that provides it and keeps the transactions whose proofs verify.
+func (lc *Client) syncWatchedTxs(nodes []nd.Node) {
+	chain, err := lc.Repos.LightRepo.GetChain()
+	if err != nil {
+		utils.LogError("Light chain currently not available", err.Error())
+		return
+	}
+
+	for _, address := range chain.Watched {
+		for _, node := range nodes {
+			if err := lc.syncAddressTxs(node, address); err != nil {
+				utils.LogError("Failed to sync address transactions", node.GetHost(), err.Error())
+				continue
+			}
+			break
+		}
+	}
+}
+
+func (lc *Client) syncAddressTxs(node nd.Node, address string) error {
+	cursor := ""
+	for {
+		page, err := node_client.GetAddressTxs(node, address, cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range page.Items {
+			chain, err := lc.Repos.LightRepo.GetChain()
+			if err != nil {
+				return err
+			}
+
+			if chain.HasTx(tx.Id) || tx.BlockHeight > chain.Height() {
+				continue
+			}
+
+			proof, err := node_client.GetInclusionProof(node, tx.Id)
+			if err != nil {
+				return err
+			}
+
+			if err := lc.Repos.LightRepo.AddProvenTx(proof); err != nil {
+				utils.LogError("Rejected transaction proof", tx.Id, err.Error())
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func (lc *Client) Run() {
+	for {
+		nodes, err := lc.getNodes()
+		if err != nil {
+			utils.LogError("Light sync [FAIL]", err.Error())
+		} else {
+			lc.syncHeaders(nodes)
+			lc.syncWatchedTxs(nodes)
+		}
+
+		time.Sleep(time.Duration(lc.Config.LightSyncIntervalInSec) * time.Second)
+	}
+}
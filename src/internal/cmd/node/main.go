@@ -3,18 +3,35 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/antavelos/blockchain/src/internal/cmd/node/api"
 	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
 	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/finality"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/light"
 	"github.com/antavelos/blockchain/src/internal/cmd/node/miner"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/pool"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/pruner"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/rebroadcaster"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/snapshots"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
 	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
 	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
 	"github.com/antavelos/blockchain/src/pkg/utils"
 )
 
+const reindexCommand = "reindex"
+const snapshotCommand = "snapshot"
+
 func main() {
-	mine := flag.Bool("mine", false, "Indicates whether it will run as well as miner")
+	mine := flag.Bool("mine", false, "Indicates whether mining starts along with the node")
+	poolMode := flag.Bool("pool", false, "Runs a pool server serving work to external miners")
+	lightMode := flag.Bool("light", false, "Runs as a light node keeping block headers only")
+	watch := flag.String("watch", "", "Comma separated addresses watched by a light node")
 	flag.Parse()
 
 	config, err := cfg.NewConfig()
@@ -26,20 +43,128 @@ func main() {
 		BlockchainFilename: config.Get("BLOCKCHAIN_FILENAME"),
 		NodeFilename:       config.Get("NODES_FILENAME"),
 		WalletFilename:     config.Get("WALLETS_FILENAME"),
+		SubmissionFilename: config.Get("SUBMISSIONS_FILENAME"),
+		IndexFilename:      config.Get("INDEX_FILENAME"),
+		LightFilename:      config.Get("LIGHT_FILENAME"),
+		SnapshotsDir:       config.Get("SNAPSHOTS_DIR"),
 	})
+	repos.BlockchainRepo.Consensus = config.Consensus
+	repos.BlockchainRepo.Finality = config.Finality
+	repos.BlockchainRepo.Limits = config.Genesis.Limits
+	repos.BlockchainRepo.Timestamps = config.TimestampRules()
+
+	if flag.Arg(0) == reindexCommand {
+		if err := repos.BlockchainRepo.Reindex(); err != nil {
+			utils.LogFatal("Reindex failed", err.Error())
+		}
+		utils.LogInfo("Reindex [OK]")
+		return
+	}
+
+	if flag.Arg(0) == snapshotCommand {
+		runSnapshotCommand(config, repos, flag.Arg(1), flag.Arg(2))
+		return
+	}
+
+	if *lightMode {
+		runLightNode(config, repos, *watch)
+		return
+	}
+
+	clock := nettime.NewClock(time.Duration(config.MaxClockAdjustmentInSec) * time.Second)
+	repos.BlockchainRepo.Clock = clock
 
 	bus := events.NewEventBus(config, repos)
 
 	bus.Handle(eventbus.DataEvent{Ev: events.InitNodeEvent})
 
+	miner := miner.NewMiner(bus, config, repos, clock)
 	if *mine {
-		miner := miner.NewMiner(bus, config, repos)
-		go miner.Run()
+		miner.Start()
 	}
+	go miner.Run()
+
+	rebroadcaster := rebroadcaster.NewRebroadcaster(bus, config, repos)
+	go rebroadcaster.Run()
+
+	snapshotter := snapshots.NewSnapshotter(config, repos)
+	go snapshotter.Run()
+
+	pruner := pruner.NewPruner(config, repos)
+	go pruner.Run()
 
 	// TODO: add a periodic longest blockchain resolve
 
-	apiHandler := api.NewRouteHandler(bus, repos)
+	finalityMonitor := finality.NewMonitor(bus, config, repos)
+
+	apiHandler := api.NewRouteHandler(bus, repos, miner, finalityMonitor, clock)
+
+	if *poolMode {
+		apiHandler.Pool = pool.NewServer(bus, config, repos, miner)
+		go func() {
+			if err := apiHandler.Pool.Run(); err != nil {
+				utils.LogFatal("Pool server error", err.Error())
+			}
+		}()
+	}
+
 	router := apiHandler.InitRouter()
 	router.Run(fmt.Sprintf(":%v", config.Get("PORT")))
 }
+
+func runLightNode(config *cfg.Config, repos *rep.Repos, watch string) {
+	for _, address := range strings.Split(watch, ",") {
+		if address == "" {
+			continue
+		}
+		if err := repos.LightRepo.Watch(address); err != nil {
+			utils.LogFatal("Failed to watch address", address, err.Error())
+		}
+	}
+
+	client := light.NewClient(config, repos)
+	go client.Run()
+
+	apiHandler := light.NewRouteHandler(repos)
+	router := apiHandler.InitRouter()
+	router.Run(fmt.Sprintf(":%v", config.Get("PORT")))
+}
+
+// runSnapshotCommand handles `node snapshot create` and
+// `node snapshot restore <file>`.
+func runSnapshotCommand(config *cfg.Config, repos *rep.Repos, action string, filename string) {
+	snapshotter := snapshots.NewSnapshotter(config, repos)
+
+	switch action {
+	case "create":
+		snapshot, err := snapshotter.Create(0)
+		if err != nil {
+			utils.LogFatal("Snapshot creation failed", err.Error())
+		}
+		utils.LogInfo("Snapshot [OK]", snapshot.Height)
+
+	case "restore":
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			utils.LogFatal("Failed to read snapshot file", err.Error())
+		}
+
+		snapshot, err := bc.UnmarshalSnapshot(data)
+		if err != nil {
+			utils.LogFatal("Invalid snapshot file", err.Error())
+		}
+
+		nodes, err := repos.NodeRepo.GetNodes()
+		if err != nil {
+			utils.LogFatal("Failed to load nodes", err.Error())
+		}
+
+		if err := snapshotter.Restore(snapshot, nodes); err != nil {
+			utils.LogFatal("Snapshot restore failed", err.Error())
+		}
+		utils.LogInfo("Snapshot restore [OK]", snapshot.Height)
+
+	default:
+		utils.LogFatal("Unknown snapshot command", action)
+	}
+}
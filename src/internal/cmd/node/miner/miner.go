@@ -1,26 +1,194 @@
 package miner
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
 	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
 	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
 	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
 	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
 	"github.com/antavelos/blockchain/src/pkg/utils"
 )
 
+var errNothingToMine = utils.GenericError{Msg: "no pending transactions found"}
+var errStaleTemplate = utils.GenericError{Msg: "block template became stale"}
+var errInterrupted = utils.GenericError{Msg: "mining interrupted"}
+var errNotInTurn = utils.GenericError{Msg: "not in turn to seal the next block"}
+
+// Miner mines blocks while running. It can be started, stopped and
+// reconfigured at runtime.
 type Miner struct {
 	Bus    *eventbus.Bus
 	Config *cfg.Config
 	Repos  *rep.Repos
+	Clock  *nettime.Clock
+
+	m             sync.Mutex
+	running       bool
+	workers       int
+	rewardAddress string
+	template      *bc.Block
+	cancel        context.CancelFunc
+	startedAt     time.Time
+	hashRate      float64
+	blocksFound   int
+	staleBlocks   int
+	hashes        atomic.Uint64
+	control       chan struct{}
+	templates     map[string]bc.Block
+	templateIds   []string
+}
+
+func NewMiner(bus *eventbus.Bus, config *cfg.Config, repos *rep.Repos, clock *nettime.Clock) *Miner {
+	return &Miner{
+		Bus:       bus,
+		Config:    config,
+		Repos:     repos,
+		Clock:     clock,
+		workers:   config.MiningWorkers,
+		control:   make(chan struct{}, 1),
+		templates: make(map[string]bc.Block),
+	}
+}
+
+// notify wakes up Run and interrupts the block being mined so that a change
+// of the miner's settings takes effect immediately.
+func (m *Miner) notify() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	select {
+	case m.control <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Miner) Start() {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.running = true
+	m.notify()
+}
+
+func (m *Miner) Stop() {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.running = false
+	m.notify()
+}
+
+func (m *Miner) IsRunning() bool {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	return m.running
+}
+
+func (m *Miner) SetWorkers(workers int) error {
+	if workers < 1 {
+		return utils.GenericError{Msg: "workers should be at least 1"}
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.workers = workers
+	m.notify()
+
+	return nil
+}
+
+// SetRewardAddress sets the address the rewards of the mined blocks are sent
+// to. An empty address restores the configured reward addresses or, without
+// any, the node's own wallet.
+func (m *Miner) SetRewardAddress(address string) error {
+	if address != "" && !wallet.IsValidAddress(address) {
+		return utils.GenericError{Msg: "invalid reward address"}
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.rewardAddress = address
+
+	return nil
+}
+
+func (m *Miner) Status() mining.Status {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	status := mining.Status{
+		Running:       m.running,
+		Workers:       m.workers,
+		RewardAddress: m.rewardAddress,
+		RewardSplits:  m.Config.RewardSplits,
+		HashRate:      m.hashRate,
+		BlocksFound:   m.blocksFound,
+		StaleBlocks:   m.staleBlocks,
+	}
+
+	if m.template != nil {
+		status.Template = mining.NewTemplate(*m.template)
+		status.HashRate = m.currentHashRate()
+	}
+
+	return status
 }
 
-func NewMiner(bus *eventbus.Bus, config *cfg.Config, repos *rep.Repos) *Miner {
-	return &Miner{Bus: bus, Config: config, Repos: repos}
+func (m *Miner) currentHashRate() float64 {
+	elapsed := time.Since(m.startedAt).Seconds()
+	if elapsed == 0 {
+		return 0
+	}
+
+	return float64(m.hashes.Load()) / elapsed
+}
+
+// begin records the block about to be mined and returns the context its
+// search runs with along with the number of workers to use.
+func (m *Miner) begin(block bc.Block) (context.Context, context.CancelFunc, int) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.template = &block
+	m.cancel = cancel
+	m.startedAt = time.Now()
+	m.hashes.Store(0)
+
+	return ctx, cancel, m.workers
+}
+
+func (m *Miner) end(found bool, stale bool) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.hashRate = m.currentHashRate()
+	m.cancel()
+	m.cancel = nil
+	m.template = nil
+
+	if found {
+		m.blocksFound++
+	}
+	if stale {
+		m.staleBlocks++
+	}
 }
 
 func (m *Miner) shareBlock(block bc.Block) error {
@@ -47,57 +215,277 @@ func (m *Miner) shareBlock(block bc.Block) error {
 	return nil
 }
 
-func (m *Miner) mine() (bc.Block, error) {
+// getBlockchain loads the blockchain to build a template on after evicting
+// the pending transactions that no longer verify, so that a single invalid
+// transaction cannot keep every template from being accepted.
+func (m *Miner) getBlockchain() (*bc.Blockchain, error) {
+	dropped, err := m.Repos.BlockchainRepo.DropInvalidTxs()
+	if err != nil {
+		return nil, utils.GenericError{Msg: "blockchain currently not available"}
+	}
+
+	for _, tx := range dropped {
+		utils.LogError("Dropped invalid pending transaction", tx.Id)
+	}
+
 	blockchain, err := m.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return nil, utils.GenericError{Msg: "blockchain currently not available"}
+	}
 
+	return blockchain, nil
+}
+
+// newTemplate builds the block to be mined on top of the current tip, starting
+// with the coinbase transactions paying the block reward. A block without
+// pending transactions is built only when allowEmpty is set.
+func (m *Miner) newTemplate(allowEmpty bool) (bc.Block, error) {
+	blockchain, err := m.getBlockchain()
 	if err != nil {
-		return bc.Block{}, utils.GenericError{Msg: "blockchain currently not available"}
+		return bc.Block{}, err
+	}
+
+	if !allowEmpty && !blockchain.HasPendingTxs() {
+		return bc.Block{}, errNothingToMine
 	}
 
-	if !blockchain.HasPendingTxs() {
-		return bc.Block{}, utils.GenericError{Msg: "no pending transactions found"}
+	splits, err := m.rewardSplits("")
+	if err != nil {
+		utils.LogError("Block reward [FAIL]", err.Error())
 	}
 
-	block, err := blockchain.NewBlock(m.Config.DefaultTxsPerBlock)
+	block, err := blockchain.AssembleBlock(m.Config.Consensus, m.Config.AssemblyPolicy, m.Config.BlockLimits(), m.coinbase(splits))
 	if err != nil {
 		return bc.Block{}, err
 	}
+	block.Timestamp = m.Config.TimestampRules().NextTimestamp(blockchain, m.Clock.Now())
 
-	utils.LogInfo("Mining...")
-	for !block.IsValid(m.Config.DefaultMiningDifficulty) {
-		block.Nonce += 1
+	return block, nil
+}
+
+// isStale reports whether the block no longer extends the tip or no longer
+// holds the pending transactions a new template would.
+func (m *Miner) isStale(block bc.Block) bool {
+	template, err := m.newTemplate(true)
+	if err != nil {
+		return true
 	}
-	utils.LogInfo("New block mined with nonce", block.Nonce)
 
-	err = m.shareBlock(block)
+	return !bytes.Equal(template.PrevHash, block.PrevHash) ||
+		!bytes.Equal(bc.MerkleRoot(m.pendingTxs(template)), bc.MerkleRoot(m.pendingTxs(block)))
+}
+
+// pendingTxs returns the transactions of the block but its coinbase ones,
+// which differ between otherwise equal templates.
+func (m *Miner) pendingTxs(block bc.Block) []bc.Transaction {
+	return utils.Filter(block.Txs, func(tx bc.Transaction) bool {
+		return tx.Body.Sender != m.Config.CoinBaseSenderAddress
+	})
+}
+
+// watch cancels the mining of the block as soon as it becomes stale: when the
+// tip changes or a change of the transaction pool changes the template.
+func (m *Miner) watch(ctx context.Context, cancel context.CancelFunc, block bc.Block, stale *atomic.Bool, txPoolChanged, tipChanged <-chan eventbus.DataEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tipChanged:
+		case <-txPoolChanged:
+			if !m.isStale(block) {
+				continue
+			}
+		}
+
+		stale.Store(true)
+		cancel()
+		return
+	}
+}
+
+// drain discards a pending notification, which the template about to be
+// built already accounts for.
+func drain(ch <-chan eventbus.DataEvent) {
+	select {
+	case <-ch:
+	default:
+	}
+}
+
+func (m *Miner) mine(txPoolChanged, tipChanged <-chan eventbus.DataEvent) (bc.Block, error) {
+	drain(txPoolChanged)
+	drain(tipChanged)
+
+	block, err := m.newTemplate(m.Config.MineEmptyBlocks)
 	if err != nil {
 		return bc.Block{}, err
 	}
 
-	err = blockchain.AddBlock(block)
+	ctx, cancel, workers := m.begin(block)
+
+	var stale atomic.Bool
+	watched := make(chan struct{})
+	go func() {
+		m.watch(ctx, cancel, block, &stale, txPoolChanged, tipChanged)
+		close(watched)
+	}()
+
+	utils.LogInfo("Mining...")
+	opts := m.sealOptions(workers)
+	header, err := m.Config.Consensus.Seal(ctx, block.BlockHeader, opts)
+	// leave the notifications arriving from now on to Run
+	cancel()
+	<-watched
+	m.end(err == nil, stale.Load())
+
+	if stale.Load() {
+		return bc.Block{}, errStaleTemplate
+	}
+
+	if err == bc.ErrSealInterrupted {
+		return bc.Block{}, errInterrupted
+	}
+
+	if err == bc.ErrNotInTurn {
+		return bc.Block{}, errNotInTurn
+	}
+
 	if err != nil {
 		return bc.Block{}, err
 	}
 
-	err = m.Repos.BlockchainRepo.ReplaceBlockchain(*blockchain)
+	block.BlockHeader = header
+	utils.LogInfo("New block mined with nonce", block.Nonce)
+
+	return block, m.publish(block)
+}
+
+// sealOptions gathers what the consensus may need to seal a block on top of
+// the current tip.
+func (m *Miner) sealOptions(workers int) bc.SealOptions {
+	opts := bc.SealOptions{Workers: workers, Hashes: &m.hashes}
+
+	if blockchain, err := m.Repos.BlockchainRepo.GetBlockchain(); err == nil {
+		opts.Parent = blockchain.State
+	}
+
+	if wallets, err := m.Repos.WalletRepo.GetWallets(); err == nil && len(wallets) > 0 {
+		opts.Signer = &wallets[0]
+	}
+
+	return opts
+}
+
+// publish shares the block with the other nodes and adds it to the local
+// blockchain.
+func (m *Miner) publish(block bc.Block) error {
+	err := m.shareBlock(block)
 	if err != nil {
-		return bc.Block{}, utils.GenericError{Msg: "failed to update blockchain"}
+		return err
 	}
 
-	return block, nil
+	err = m.Repos.BlockchainRepo.AddBlock(block)
+	if err != nil {
+		return utils.GenericError{Msg: "failed to update blockchain", Extra: err}
+	}
+
+	return nil
+}
+
+// nextBlockIn returns how long to wait before mining the next block, honouring
+// the minimum block interval and, for empty blocks, the maximum one. It
+// reports false when there is nothing to mine.
+func (m *Miner) nextBlockIn() (time.Duration, bool) {
+	blockchain, err := m.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return 0, false
+	}
+
+	sinceLastBlock := time.Since(time.UnixMilli(blockchain.LastBlock().Timestamp))
+	wait := time.Duration(m.Config.MinBlockIntervalInSec)*time.Second - sinceLastBlock
+
+	if blockchain.HasPendingTxs() {
+		return wait, true
+	}
+
+	if m.Config.MineEmptyBlocks {
+		if untilMax := time.Duration(m.Config.MaxBlockIntervalInSec)*time.Second - sinceLastBlock; untilMax > wait {
+			wait = untilMax
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// failureBackoff is how long the miner waits before retrying after failing
+// to mine a block.
+func (m *Miner) failureBackoff() time.Duration {
+	if m.Config.MinBlockIntervalInSec > 0 {
+		return time.Duration(m.Config.MinBlockIntervalInSec) * time.Second
+	}
+
+	return time.Second
 }
 
+// Run mines, while the miner is running, whenever there is something to
+// mine, waking up on transaction pool and tip changes instead of polling.
 func (m *Miner) Run() {
+	txPoolChanged := m.Bus.Subscribe(events.TxPoolChangedEvent)
+	tipChanged := m.Bus.Subscribe(events.TipChangedEvent)
+
 	for {
-		block, err := m.mine()
-		if err != nil {
-			utils.LogError("New block [FAIL]", err.Error())
-			m.Bus.Handle(eventbus.DataEvent{Ev: events.BlockMiningFailedEvent})
-		} else {
-			utils.LogInfo("New block [OK]", block.Idx)
-			m.Bus.Handle(eventbus.DataEvent{Ev: events.BlockMinedEvent})
+		if !m.IsRunning() {
+			<-m.control
+			continue
+		}
+
+		wait, ok := m.nextBlockIn()
+
+		if ok && wait <= 0 {
+			// the attempt runs with the current settings already
+			select {
+			case <-m.control:
+			default:
+			}
+
+			block, err := m.mine(txPoolChanged, tipChanged)
+			switch {
+			case err == errNothingToMine, err == errInterrupted:
+			case err == errStaleTemplate:
+				utils.LogInfo("Block template is stale, rebuilding")
+			case err == errNotInTurn:
+				// another validator seals the next block unless it is
+				// missing, in which case our out of turn delay passes
+				select {
+				case <-tipChanged:
+				case <-time.After(m.failureBackoff()):
+				case <-m.control:
+				}
+			case err != nil:
+				utils.LogError("New block [FAIL]", err.Error())
+				m.Bus.Handle(eventbus.DataEvent{Ev: events.BlockMiningFailedEvent})
+				// back off instead of failing over and over
+				select {
+				case <-time.After(m.failureBackoff()):
+				case <-m.control:
+				}
+			default:
+				utils.LogInfo("New block [OK]", block.Idx)
+			}
+			continue
+		}
+
+		var due <-chan time.Time
+		if ok {
+			due = time.After(wait)
 		}
 
-		time.Sleep(5 * time.Second)
+		select {
+		case <-txPoolChanged:
+		case <-tipChanged:
+		case <-m.control:
+		case <-due:
+		}
 	}
 }
@@ -0,0 +1,257 @@
+package miner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
+)
+
+// unreachableDifficulty cannot be met, so that sealing only ends when
+// interrupted.
+const unreachableDifficulty = 32
+
+type testMiner struct {
+	*Miner
+	dir  string
+	john *wallet.Wallet
+	jane *wallet.Wallet
+}
+
+// newTestMiner returns a miner of the given proof of work difficulty on top
+// of a blockchain funding John, without any other node to share blocks with.
+func newTestMiner(t *testing.T, difficulty int) testMiner {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "nodes.json"), []byte("[]"), 0644); err != nil {
+		t.Fatalf("Expected nodes file but got: %v", err)
+	}
+
+	repos := &rep.Repos{
+		BlockchainRepo: rep.NewBlockchainRepo(database.NewDB(filepath.Join(dir, "blockchain.json")), nil),
+		NodeRepo:       rep.NewNodeRepo(database.NewDB(filepath.Join(dir, "nodes.json"))),
+		WalletRepo:     rep.NewWalletRepo(database.NewDB(filepath.Join(dir, "wallets.json"))),
+	}
+
+	john, _ := wallet.NewWallet()
+	jane, _ := wallet.NewWallet()
+
+	blockchain := bc.NewBlockchain()
+	blockchain.TxPool = []bc.Transaction{{Id: "funding", Body: bc.TransactionBody{Sender: "0", Recipient: john.AddressString(), Amount: 10}}}
+	block, _ := blockchain.NewBlock(1)
+	if err := blockchain.AddBlock(block); err != nil {
+		t.Fatalf("Expected block to be added but got: %v", err)
+	}
+	if err := repos.BlockchainRepo.ReplaceBlockchain(*blockchain); err != nil {
+		t.Fatalf("Expected blockchain to be saved but got: %v", err)
+	}
+
+	config := &cfg.Config{
+		CoinBaseSenderAddress: "0",
+		DefaultTxsPerBlock:    10,
+		DefaultRewardAmount:   1,
+		MaxBlockBytes:         1000000,
+		MedianTimeSpan:        11,
+		MaxFutureDriftInSec:   15,
+		AssemblyPolicy:        bc.FIFOPolicy{},
+		RewardSplits:          []mining.RewardSplit{{Address: jane.AddressString(), Percentage: 100}},
+		Consensus:             bc.NewProofOfWork(difficulty),
+	}
+
+	return testMiner{Miner: NewMiner(eventbus.NewBus(), config, repos, nettime.NewClock(0)), dir: dir, john: john, jane: jane}
+}
+
+// sendTx adds a transaction of John to the pool and announces it.
+func (m testMiner) sendTx(t *testing.T, amount float64) bc.Transaction {
+	blockchain, _ := m.Repos.BlockchainRepo.GetBlockchain()
+
+	tx, _ := bc.NewTransaction(*m.john, *m.jane, amount, blockchain.NextNonce(m.john.AddressString()))
+	tx, err := m.Repos.BlockchainRepo.AddTx(tx)
+	if err != nil {
+		t.Fatalf("Expected transaction to be added but got: %v", err)
+	}
+	m.Bus.Handle(eventbus.DataEvent{Ev: events.TxPoolChangedEvent, Data: tx})
+
+	return tx
+}
+
+// startMining mines in the background once the pool has a transaction and
+// returns the outcome once the miner is done.
+func (m testMiner) startMining(t *testing.T) <-chan error {
+	txPoolChanged := m.Bus.Subscribe(events.TxPoolChangedEvent)
+	tipChanged := m.Bus.Subscribe(events.TipChangedEvent)
+
+	m.sendTx(t, 1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.mine(txPoolChanged, tipChanged)
+		done <- err
+	}()
+
+	waitFor(t, "the miner to start", func() bool { return m.Status().Template != nil })
+
+	return done
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %v in time", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func expectDone(t *testing.T, done <-chan error) error {
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the miner to stop in time")
+		return nil
+	}
+}
+
+func TestMineCancelsTemplateOnTipChange(t *testing.T) {
+	m := newTestMiner(t, unreachableDifficulty)
+	done := m.startMining(t)
+
+	m.Bus.Handle(eventbus.DataEvent{Ev: events.TipChangedEvent})
+
+	if err := expectDone(t, done); err != errStaleTemplate {
+		t.Errorf("Expected the template to become stale but got %v", err)
+	}
+
+	if stale := m.Status().StaleBlocks; stale != 1 {
+		t.Errorf("Expected 1 stale block but got %v", stale)
+	}
+}
+
+func TestMineCancelsTemplateOnlyWhenThePoolChangesIt(t *testing.T) {
+	m := newTestMiner(t, unreachableDifficulty)
+	done := m.startMining(t)
+
+	m.Bus.Handle(eventbus.DataEvent{Ev: events.TxPoolChangedEvent})
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the template to be kept but got %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	m.sendTx(t, 2)
+
+	if err := expectDone(t, done); err != errStaleTemplate {
+		t.Errorf("Expected the template to become stale but got %v", err)
+	}
+}
+
+func TestRunMinesOnTxPoolChange(t *testing.T) {
+	m := newTestMiner(t, 0)
+	m.Start()
+	go m.Run()
+	defer m.Stop()
+
+	tx := m.sendTx(t, 1)
+
+	var blockchain *bc.Blockchain
+	waitFor(t, "a block to be mined", func() bool {
+		blockchain, _ = m.Repos.BlockchainRepo.GetBlockchain()
+		return len(blockchain.Blocks) == 3
+	})
+
+	block := blockchain.LastBlock()
+	if len(block.Txs) != 2 || block.Txs[1].Id != tx.Id {
+		t.Fatalf("Expected the block to hold the coinbase and the transaction but got %v", block.Txs)
+	}
+
+	if coinbase := block.Txs[0].Body; coinbase.Sender != "0" || coinbase.Recipient != m.jane.AddressString() || coinbase.Amount != 1 {
+		t.Errorf("Expected the coinbase to reward Jane but got %v", coinbase)
+	}
+
+	if blockchain.HasPendingTxs() {
+		t.Errorf("Expected no pending transactions after the block but got %v", blockchain.TxPool)
+	}
+}
+
+func TestRunBacksOffAfterFailure(t *testing.T) {
+	m := newTestMiner(t, 0)
+	failed := m.Bus.Subscribe(events.BlockMiningFailedEvent)
+
+	// the nodes cannot be loaded, so that the mined blocks cannot be shared
+	if err := os.WriteFile(filepath.Join(m.dir, "nodes.json"), []byte("invalid"), 0644); err != nil {
+		t.Fatalf("Expected nodes file but got: %v", err)
+	}
+
+	m.sendTx(t, 1)
+	m.Start()
+	go m.Run()
+	defer m.Stop()
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the mining to fail in time")
+	}
+
+	select {
+	case <-failed:
+		t.Errorf("Expected the miner to back off before retrying")
+	case <-time.After(m.failureBackoff() / 2):
+	}
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the miner to retry after backing off")
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	tests := []struct {
+		minBlockInterval int
+		expected         time.Duration
+	}{
+		{0, time.Second},
+		{3, 3 * time.Second},
+	}
+
+	for _, test := range tests {
+		m := Miner{Config: &cfg.Config{MinBlockIntervalInSec: test.minBlockInterval}}
+
+		if backoff := m.failureBackoff(); backoff != test.expected {
+			t.Errorf("Expected backoff %v for interval %v but got %v", test.expected, test.minBlockInterval, backoff)
+		}
+	}
+}
+
+func TestNextBlockIn(t *testing.T) {
+	m := newTestMiner(t, 0)
+	m.Config.MinBlockIntervalInSec = 0
+	m.Config.MaxBlockIntervalInSec = 60
+
+	if _, ok := m.nextBlockIn(); ok {
+		t.Errorf("Expected nothing to mine without pending transactions")
+	}
+
+	m.Config.MineEmptyBlocks = true
+	if wait, ok := m.nextBlockIn(); !ok || wait <= 0 {
+		t.Errorf("Expected an empty block to wait for the max interval but got %v, %v", wait, ok)
+	}
+
+	m.sendTx(t, 1)
+	if wait, ok := m.nextBlockIn(); !ok || wait > 0 {
+		t.Errorf("Expected a block to be due but got %v, %v", wait, ok)
+	}
+}
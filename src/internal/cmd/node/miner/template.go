@@ -0,0 +1,166 @@
+package miner
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+	"github.com/google/uuid"
+)
+
+const maxTemplates = 100
+
+var ErrTemplateNotFound = utils.GenericError{Msg: "block template not found"}
+var ErrInvalidProofOfWork = utils.GenericError{Msg: "invalid proof of work"}
+
+func (m *Miner) walletAddress() (string, error) {
+	wallets, err := m.Repos.WalletRepo.GetWallets()
+	if err != nil || len(wallets) == 0 {
+		return "", utils.GenericError{Msg: "node has no wallet"}
+	}
+
+	return wallets[0].AddressString(), nil
+}
+
+// rewardSplits resolves who gets rewarded for a block: the given address, or
+// else the address set at runtime, the configured reward addresses and
+// finally the node's own wallet.
+func (m *Miner) rewardSplits(address string) ([]mining.RewardSplit, error) {
+	if address != "" {
+		if !wallet.IsValidAddress(address) {
+			return nil, utils.GenericError{Msg: "invalid reward address"}
+		}
+		return []mining.RewardSplit{{Address: address, Percentage: 100}}, nil
+	}
+
+	if address = m.Status().RewardAddress; address != "" {
+		return []mining.RewardSplit{{Address: address, Percentage: 100}}, nil
+	}
+
+	if len(m.Config.RewardSplits) > 0 {
+		return m.Config.RewardSplits, nil
+	}
+
+	address, err := m.walletAddress()
+	if err != nil {
+		return nil, utils.GenericError{Msg: "node has no wallet and no reward addresses configured"}
+	}
+
+	return []mining.RewardSplit{{Address: address, Percentage: 100}}, nil
+}
+
+// coinbase builds the coinbase transactions paying the block reward to the
+// splits.
+func (m *Miner) coinbase(splits []mining.RewardSplit) []bc.Transaction {
+	coinbase := mining.RewardTxs(splits, m.Config.DefaultRewardAmount, m.Config.CoinBaseSenderAddress)
+	for i := range coinbase {
+		coinbase[i].Id = uuid.NewString()
+		coinbase[i].Timestamp = time.Now().UnixMilli()
+	}
+
+	return coinbase
+}
+
+// storeTemplate keeps the block until it gets submitted. Templates which no
+// longer extend the tip are dropped, as are the oldest ones past
+// maxTemplates.
+func (m *Miner) storeTemplate(id string, block bc.Block) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	ids := []string{}
+	for _, templateId := range m.templateIds {
+		if bytes.Equal(m.templates[templateId].PrevHash, block.PrevHash) {
+			ids = append(ids, templateId)
+		} else {
+			delete(m.templates, templateId)
+		}
+	}
+
+	if len(ids) >= maxTemplates {
+		delete(m.templates, ids[0])
+		ids = ids[1:]
+	}
+
+	m.templates[id] = block
+	m.templateIds = append(ids, id)
+}
+
+func (m *Miner) takeTemplate(id string) (bc.Block, bool) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	block, found := m.templates[id]
+	if found {
+		delete(m.templates, id)
+		m.templateIds = utils.Filter(m.templateIds, func(templateId string) bool {
+			return templateId != id
+		})
+	}
+
+	return block, found
+}
+
+// NewBlockTemplate builds a block for an external miner. The block starts
+// with the coinbase transactions rewarding the given address or, if empty,
+// the node's configured reward addresses.
+func (m *Miner) NewBlockTemplate(rewardAddress string) (mining.BlockTemplate, error) {
+	splits, err := m.rewardSplits(rewardAddress)
+	if err != nil {
+		return mining.BlockTemplate{}, err
+	}
+
+	blockchain, err := m.getBlockchain()
+	if err != nil {
+		return mining.BlockTemplate{}, err
+	}
+
+	coinbase := m.coinbase(splits)
+	block, err := blockchain.AssembleBlock(m.Config.Consensus, m.Config.AssemblyPolicy, m.Config.BlockLimits(), coinbase)
+	if err != nil {
+		return mining.BlockTemplate{}, err
+	}
+	block.Timestamp = m.Config.TimestampRules().NextTimestamp(blockchain, m.Clock.Now())
+
+	id := uuid.NewString()
+	m.storeTemplate(id, block)
+
+	difficulty := m.Config.Consensus.Difficulty(blockchain.LastBlock().BlockHeader)
+
+	return mining.NewBlockTemplate(id, block, difficulty, len(coinbase)), nil
+}
+
+// SubmitBlock completes the block of the template with the submitted nonce
+// and, if valid, publishes it as if it had been mined locally.
+func (m *Miner) SubmitBlock(submission mining.BlockSubmission) (bc.Block, error) {
+	block, found := m.takeTemplate(submission.TemplateId)
+	if !found {
+		return bc.Block{}, ErrTemplateNotFound
+	}
+
+	blockchain, err := m.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		m.storeTemplate(submission.TemplateId, block)
+		return bc.Block{}, utils.GenericError{Msg: "blockchain currently not available"}
+	}
+
+	block.Nonce = submission.Nonce
+	if m.Config.Consensus.VerifyHeader(blockchain.LastBlock().BlockHeader, block.BlockHeader) != nil {
+		m.storeTemplate(submission.TemplateId, block)
+		return bc.Block{}, ErrInvalidProofOfWork
+	}
+
+	if err := m.publish(block); err != nil {
+		return bc.Block{}, err
+	}
+
+	utils.LogInfo("New submitted block [OK]", block.Idx)
+	m.Bus.Handle(eventbus.DataEvent{Ev: events.TipChangedEvent, Data: block})
+
+	return block, nil
+}
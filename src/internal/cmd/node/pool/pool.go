@@ -0,0 +1,421 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/miner"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
+	pl "github.com/antavelos/blockchain/src/internal/pkg/models/pool"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// maxJobs is the number of recent jobs shares are still accepted for.
+const maxJobs = 10
+
+// Server serves work units built from the node's block templates to the
+// connected miners, accounts their shares and, when a share solves the block,
+// submits it and pays the reward out proportionally to the round's shares.
+type Server struct {
+	Bus    *eventbus.Bus
+	Config *cfg.Config
+	Repos  *rep.Repos
+	Miner  *miner.Miner
+
+	m          sync.Mutex
+	accounting *pl.Accounting
+	jobs       map[string]*servedJob
+	jobIds     []string
+	conns      map[*conn]bool
+}
+
+// servedJob is a job served to the miners along with the tip it builds on
+// and the nonces already submitted for it.
+type servedJob struct {
+	pl.Job
+	prevHash []byte
+	shares   map[int64]bool
+}
+
+type conn struct {
+	c       net.Conn
+	m       sync.Mutex
+	encoder *json.Encoder
+	address string
+}
+
+func (c *conn) send(message pl.Message) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if err := c.encoder.Encode(message); err != nil {
+		utils.LogError("Failed to send pool message", c.c.RemoteAddr().String(), err.Error())
+	}
+}
+
+func (c *conn) respond(id int64, result any, err error) {
+	message := pl.Message{Id: id, Result: result}
+	if err != nil {
+		message.Error = err.Error()
+	}
+
+	c.send(message)
+}
+
+func NewServer(bus *eventbus.Bus, config *cfg.Config, repos *rep.Repos, miner *miner.Miner) *Server {
+	return &Server{
+		Bus:        bus,
+		Config:     config,
+		Repos:      repos,
+		Miner:      miner,
+		accounting: pl.NewAccounting(),
+		jobs:       make(map[string]*servedJob),
+		conns:      make(map[*conn]bool),
+	}
+}
+
+// Accounting returns a copy of the pool's share accounting.
+func (s *Server) Accounting() pl.Accounting {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	accounting := pl.Accounting{
+		Round:  make(map[string]int),
+		Miners: make(map[string]pl.MinerStats),
+		Blocks: s.accounting.Blocks,
+	}
+	for address, shares := range s.accounting.Round {
+		accounting.Round[address] = shares
+	}
+	for address, stats := range s.accounting.Miners {
+		accounting.Miners[address] = stats
+	}
+
+	return accounting
+}
+
+func (s *Server) Run() error {
+	pow, ok := s.Config.Consensus.(bc.ProofOfWork)
+	if !ok {
+		return utils.GenericError{Msg: "the pool requires proof of work consensus"}
+	}
+
+	// the node's wallet receives the rewards it pays the miners out from
+	if len(s.Config.RewardSplits) > 0 {
+		return utils.GenericError{Msg: "the pool cannot run with reward addresses configured"}
+	}
+
+	if s.Config.PoolShareDifficulty >= pow.Difficulty(bc.BlockHeader{}) {
+		return utils.GenericError{Msg: "the share difficulty should be lower than the mining difficulty"}
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", s.Config.Get("POOL_PORT")))
+	if err != nil {
+		return utils.GenericError{Msg: "failed to start pool server", Extra: err}
+	}
+	defer listener.Close()
+
+	go s.refreshJobs()
+
+	utils.LogInfo("Pool server listening on", listener.Addr().String())
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			utils.LogError("Failed to accept pool connection", err.Error())
+			continue
+		}
+
+		go s.handle(&conn{c: c, encoder: json.NewEncoder(c)})
+	}
+}
+
+// refreshJobs issues a new job whenever the tip or the transaction pool
+// changes.
+func (s *Server) refreshJobs() {
+	txPoolChanged := s.Bus.Subscribe(events.TxPoolChangedEvent)
+	tipChanged := s.Bus.Subscribe(events.TipChangedEvent)
+
+	for {
+		if err := s.newJob(); err != nil {
+			utils.LogError("New pool job [FAIL]", err.Error())
+		}
+
+		select {
+		case <-txPoolChanged:
+		case <-tipChanged:
+		}
+	}
+}
+
+func (s *Server) newJob() error {
+	poolWallet, err := s.poolWallet()
+	if err != nil {
+		return err
+	}
+
+	template, err := s.Miner.NewBlockTemplate(poolWallet.AddressString())
+	if err != nil {
+		return err
+	}
+
+	job := newJob(template, s.Config.PoolShareDifficulty)
+
+	s.m.Lock()
+	s.dropJobs(func(other *servedJob) bool { return !bytes.Equal(other.prevHash, job.prevHash) })
+	s.jobs[job.Id] = job
+	s.jobIds = append(s.jobIds, job.Id)
+	if len(s.jobIds) > maxJobs {
+		delete(s.jobs, s.jobIds[0])
+		s.jobIds = s.jobIds[1:]
+	}
+
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.m.Unlock()
+
+	for _, c := range conns {
+		c.send(jobMessage(job.Job))
+	}
+
+	return nil
+}
+
+func newJob(template mining.BlockTemplate, shareDifficulty int) *servedJob {
+	return &servedJob{
+		Job: pl.Job{
+			Id:          template.Id,
+			HeaderBytes: template.HeaderBytes,
+			NonceOffset: template.NonceOffset,
+			ShareTarget: hex.EncodeToString(bc.DifficultyTarget(shareDifficulty)),
+			Target:      template.Target,
+			Difficulty:  template.Difficulty,
+		},
+		prevHash: template.Header.PrevHash,
+		shares:   make(map[int64]bool),
+	}
+}
+
+// dropJobs drops the jobs no more shares are accepted for. The caller holds
+// the lock.
+func (s *Server) dropJobs(stale func(*servedJob) bool) {
+	s.jobIds = utils.Filter(s.jobIds, func(id string) bool {
+		if stale(s.jobs[id]) {
+			delete(s.jobs, id)
+			return false
+		}
+		return true
+	})
+}
+
+func jobMessage(job pl.Job) pl.Message {
+	params, _ := json.Marshal(job)
+
+	return pl.Message{Method: pl.JobMethod, Params: params}
+}
+
+func (s *Server) currentJob() (pl.Job, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if len(s.jobIds) == 0 {
+		return pl.Job{}, false
+	}
+
+	return s.jobs[s.jobIds[len(s.jobIds)-1]].Job, true
+}
+
+func (s *Server) handle(c *conn) {
+	defer func() {
+		s.m.Lock()
+		delete(s.conns, c)
+		s.m.Unlock()
+		c.c.Close()
+	}()
+
+	scanner := bufio.NewScanner(c.c)
+	for scanner.Scan() {
+		var message pl.Message
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			c.respond(0, nil, utils.GenericError{Msg: "invalid message"})
+			continue
+		}
+
+		switch message.Method {
+		case pl.LoginMethod:
+			s.login(c, message)
+		case pl.SubmitMethod:
+			s.submit(c, message)
+		default:
+			c.respond(message.Id, nil, utils.GenericError{Msg: "unknown method"})
+		}
+	}
+}
+
+func (s *Server) login(c *conn, message pl.Message) {
+	var params pl.LoginParams
+	if err := json.Unmarshal(message.Params, &params); err != nil || !wallet.IsValidAddress(params.Address) {
+		c.respond(message.Id, nil, utils.GenericError{Msg: "invalid address"})
+		return
+	}
+
+	s.m.Lock()
+	c.address = params.Address
+	s.conns[c] = true
+	s.m.Unlock()
+
+	utils.LogInfo("Pool miner logged in", params.Address, c.c.RemoteAddr().String())
+	c.respond(message.Id, true, nil)
+
+	if job, ok := s.currentJob(); ok {
+		c.send(jobMessage(job))
+	}
+}
+
+func (s *Server) submit(c *conn, message pl.Message) {
+	s.m.Lock()
+	address := c.address
+	s.m.Unlock()
+
+	if address == "" {
+		c.respond(message.Id, nil, utils.GenericError{Msg: "not logged in"})
+		return
+	}
+
+	var params pl.SubmitParams
+	if err := json.Unmarshal(message.Params, &params); err != nil {
+		c.respond(message.Id, nil, utils.GenericError{Msg: "invalid params"})
+		return
+	}
+
+	result, err := s.acceptShare(address, params)
+	c.respond(message.Id, result, err)
+}
+
+// acceptShare verifies the share and accounts it. If it also meets the
+// network target, the block is submitted and the round's reward paid out.
+func (s *Server) acceptShare(address string, params pl.SubmitParams) (pl.SubmitResult, error) {
+	s.m.Lock()
+
+	job, found := s.jobs[params.JobId]
+
+	var err error
+	switch {
+	case !found:
+		err = utils.GenericError{Msg: "unknown or stale job"}
+	case job.shares[params.Nonce]:
+		err = utils.GenericError{Msg: "duplicate share"}
+	}
+	if err != nil {
+		s.accounting.RejectShare(address)
+		s.m.Unlock()
+		return pl.SubmitResult{}, err
+	}
+
+	hash, err := shareHash(job.Job, params.Nonce)
+	if err != nil || !bc.IsValidHash(hash, s.Config.PoolShareDifficulty) {
+		s.accounting.RejectShare(address)
+		s.m.Unlock()
+		return pl.SubmitResult{}, utils.GenericError{Msg: "share does not meet the share target"}
+	}
+
+	job.shares[params.Nonce] = true
+	s.accounting.AcceptShare(address)
+	result := pl.SubmitResult{Accepted: true, RoundShares: s.accounting.RoundShares()}
+	s.m.Unlock()
+
+	if !bc.IsValidHash(hash, job.Difficulty) {
+		return result, nil
+	}
+
+	block, err := s.Miner.SubmitBlock(mining.BlockSubmission{TemplateId: job.Id, Nonce: params.Nonce})
+	if err != nil {
+		utils.LogError("Pool block submission [FAIL]", err.Error())
+		return result, nil
+	}
+	utils.LogInfo("Pool block [OK]", block.Idx)
+
+	s.m.Lock()
+	payouts := s.accounting.CloseRound(s.Config.DefaultRewardAmount)
+	// the jobs on top of the former tip are stale now
+	s.dropJobs(func(other *servedJob) bool { return bytes.Equal(other.prevHash, job.prevHash) })
+	s.m.Unlock()
+
+	go s.payout(payouts)
+
+	result.BlockFound = true
+	return result, nil
+}
+
+func shareHash(job pl.Job, nonce int64) ([]byte, error) {
+	data, err := hex.DecodeString(job.HeaderBytes)
+	if err != nil || len(data) != bc.HeaderSize {
+		return nil, utils.GenericError{Msg: "invalid job header"}
+	}
+
+	bc.PutHeaderNonce(data, nonce)
+
+	return crypto.HashData(data), nil
+}
+
+// poolWallet is the node's wallet the pool's blocks reward and the miners
+// get paid from.
+func (s *Server) poolWallet() (wallet.Wallet, error) {
+	wallets, err := s.Repos.WalletRepo.GetWallets()
+	if err != nil || len(wallets) == 0 {
+		return wallet.Wallet{}, utils.GenericError{Msg: "node has no wallet"}
+	}
+
+	return wallets[0], nil
+}
+
+// payout sends every miner of the closed round its part of the reward from
+// the pool's wallet the block's coinbase was paid to.
+func (s *Server) payout(payouts []pl.Payout) {
+	poolWallet, err := s.poolWallet()
+	if err != nil {
+		utils.LogError("Pool payout [FAIL]", err.Error())
+		return
+	}
+
+	blockchain, err := s.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		utils.LogError("Pool payout [FAIL]", err.Error())
+		return
+	}
+	nonce := blockchain.NextNonce(poolWallet.AddressString())
+
+	for _, payout := range payouts {
+		if payout.Address == poolWallet.AddressString() {
+			continue
+		}
+
+		tx, err := bc.NewTransactionTo(poolWallet, payout.Address, payout.Amount, nonce)
+		if err == nil {
+			tx, err = s.Repos.BlockchainRepo.AddTx(tx)
+		}
+		if err != nil {
+			utils.LogError("Pool payout [FAIL]", payout.Address, err.Error())
+			continue
+		}
+
+		nonce++
+
+		utils.LogInfo("Pool payout [OK]", payout.Address, payout.Amount)
+		s.Bus.Handle(eventbus.DataEvent{Ev: events.TransactionReceivedEvent, Data: tx})
+		s.Bus.Handle(eventbus.DataEvent{Ev: events.TxPoolChangedEvent, Data: tx})
+	}
+}
@@ -0,0 +1,174 @@
+package pool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/miner"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	pl "github.com/antavelos/blockchain/src/internal/pkg/models/pool"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
+)
+
+const testDifficulty = 2
+const testShareDifficulty = 1
+
+func newTestServer(t *testing.T) *Server {
+	dir := t.TempDir()
+	repos := rep.InitRepos(rep.DBFilenames{
+		BlockchainFilename: filepath.Join(dir, "blockchain.json"),
+		NodeFilename:       filepath.Join(dir, "nodes.json"),
+		WalletFilename:     filepath.Join(dir, "wallets.json"),
+		SubmissionFilename: filepath.Join(dir, "submissions.json"),
+		IndexFilename:      filepath.Join(dir, "index.json"),
+		LightFilename:      filepath.Join(dir, "light.json"),
+		SnapshotsDir:       dir,
+	})
+	if _, err := repos.BlockchainRepo.CreateBlockchain(); err != nil {
+		t.Fatalf("Failed to create blockchain: %v", err)
+	}
+	if _, err := repos.WalletRepo.CreateWallet(); err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	config := &cfg.Config{
+		CoinBaseSenderAddress: "0",
+		DefaultTxsPerBlock:    10,
+		DefaultRewardAmount:   1.0,
+		MaxBlockBytes:         bc.UnlimitedBytes,
+		PoolShareDifficulty:   testShareDifficulty,
+		MedianTimeSpan:        11,
+		MaxFutureDriftInSec:   15,
+		AssemblyPolicy:        bc.FIFOPolicy{},
+		Genesis:               bc.DefaultGenesis(testDifficulty),
+		Consensus:             bc.NewProofOfWork(testDifficulty),
+	}
+
+	return NewServer(nil, config, repos, miner.NewMiner(nil, config, repos, nettime.NewClock(time.Minute)))
+}
+
+type testClient struct {
+	t       *testing.T
+	encoder *json.Encoder
+	scanner *bufio.Scanner
+}
+
+func (c testClient) request(id int64, method string, params any) {
+	data, _ := json.Marshal(params)
+	if err := c.encoder.Encode(pl.Message{Id: id, Method: method, Params: data}); err != nil {
+		c.t.Fatalf("Failed to send message: %v", err)
+	}
+}
+
+func (c testClient) read() pl.Message {
+	if !c.scanner.Scan() {
+		c.t.Fatalf("Expected a message but got %v", c.scanner.Err())
+	}
+
+	var message pl.Message
+	if err := json.Unmarshal(c.scanner.Bytes(), &message); err != nil {
+		c.t.Fatalf("Expected a valid message but got %v", err)
+	}
+
+	return message
+}
+
+func (c testClient) readJob() pl.Job {
+	message := c.read()
+	if message.Method != pl.JobMethod {
+		c.t.Fatalf("Expected a job but got %+v", message)
+	}
+
+	var job pl.Job
+	json.Unmarshal(message.Params, &job)
+
+	return job
+}
+
+// findShare returns a nonce meeting the share target but not the network one.
+func findShare(t *testing.T, job pl.Job) int64 {
+	for nonce := int64(0); nonce < 1<<20; nonce++ {
+		hash, err := shareHash(job, nonce)
+		if err != nil {
+			t.Fatalf("Expected a valid job but got %v", err)
+		}
+		if bc.IsValidHash(hash, testShareDifficulty) && !bc.IsValidHash(hash, testDifficulty) {
+			return nonce
+		}
+	}
+
+	t.Fatalf("Expected a share to be found")
+	return 0
+}
+
+func TestPoolProtocol(t *testing.T) {
+	server := newTestServer(t)
+	if err := server.newJob(); err != nil {
+		t.Fatalf("Expected a new job but got %v", err)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	go server.handle(&conn{c: serverConn, encoder: json.NewEncoder(serverConn)})
+
+	client := testClient{t: t, encoder: json.NewEncoder(clientConn), scanner: bufio.NewScanner(clientConn)}
+
+	minerWallet, _ := wallet.NewWallet()
+	client.request(1, pl.LoginMethod, pl.LoginParams{Address: minerWallet.AddressString()})
+	if response := client.read(); response.Id != 1 || response.Error != "" {
+		t.Fatalf("Expected the login to succeed but got %+v", response)
+	}
+	job := client.readJob()
+
+	nonce := findShare(t, job)
+	client.request(2, pl.SubmitMethod, pl.SubmitParams{JobId: job.Id, Nonce: nonce})
+	if response := client.read(); response.Id != 2 || response.Error != "" {
+		t.Errorf("Expected the share to be accepted but got %+v", response)
+	}
+
+	client.request(3, pl.SubmitMethod, pl.SubmitParams{JobId: job.Id, Nonce: nonce})
+	if response := client.read(); response.Error != "duplicate share" {
+		t.Errorf("Expected the duplicate share to be rejected but got %+v", response)
+	}
+
+	// a new tip makes the jobs built on the former one stale
+	blockchain, _ := server.Repos.BlockchainRepo.GetBlockchain()
+	block, _ := blockchain.NewBlock(10)
+	header, err := server.Config.Consensus.Seal(context.Background(), block.BlockHeader, bc.SealOptions{Workers: 1})
+	if err != nil {
+		t.Fatalf("Failed to seal block: %v", err)
+	}
+	block.BlockHeader = header
+	if err := server.Repos.BlockchainRepo.AddBlock(block); err != nil {
+		t.Fatalf("Failed to add block: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- server.newJob() }()
+	newJob := client.readJob()
+	if err := <-done; err != nil {
+		t.Fatalf("Expected a new job but got %v", err)
+	}
+
+	client.request(4, pl.SubmitMethod, pl.SubmitParams{JobId: job.Id, Nonce: findShare(t, job)})
+	if response := client.read(); response.Error != "unknown or stale job" {
+		t.Errorf("Expected the share of the stale job to be rejected but got %+v", response)
+	}
+
+	client.request(5, pl.SubmitMethod, pl.SubmitParams{JobId: newJob.Id, Nonce: findShare(t, newJob)})
+	if response := client.read(); response.Error != "" {
+		t.Errorf("Expected the share of the new job to be accepted but got %+v", response)
+	}
+
+	if accepted := server.Accounting().Miners[minerWallet.AddressString()].Accepted; accepted != 2 {
+		t.Errorf("Expected 2 accepted shares but got %v", accepted)
+	}
+}
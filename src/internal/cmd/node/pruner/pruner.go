@@ -0,0 +1,39 @@
+package pruner
+
+import (
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const pruneInterval = 10 * time.Second
+
+// Pruner drops the transactions of the blocks older than the last PruneDepth
+// ones, keeping their headers and the state they result in.
+type Pruner struct {
+	Config *cfg.Config
+	Repos  *rep.Repos
+}
+
+func NewPruner(config *cfg.Config, repos *rep.Repos) *Pruner {
+	return &Pruner{Config: config, Repos: repos}
+}
+
+func (p *Pruner) Run() {
+	if !p.Config.IsPruned() {
+		return
+	}
+
+	for {
+		pruned, err := p.Repos.BlockchainRepo.Prune(int64(p.Config.PruneDepth))
+		if err != nil {
+			utils.LogError("Blockchain pruning [FAIL]", err.Error())
+		} else if pruned {
+			utils.LogInfo("Blockchain pruning [OK]")
+		}
+
+		time.Sleep(pruneInterval)
+	}
+}
@@ -0,0 +1,123 @@
+package rebroadcaster
+
+import (
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	"github.com/antavelos/blockchain/src/internal/cmd/node/events"
+	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	idx "github.com/antavelos/blockchain/src/internal/pkg/models/index"
+	sub "github.com/antavelos/blockchain/src/internal/pkg/models/submission"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/eventbus"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// Rebroadcaster re-announces the transactions submitted to this node until they
+// get confirmed or expire.
+type Rebroadcaster struct {
+	Bus    *eventbus.Bus
+	Config *cfg.Config
+	Repos  *rep.Repos
+}
+
+func NewRebroadcaster(bus *eventbus.Bus, config *cfg.Config, repos *rep.Repos) *Rebroadcaster {
+	return &Rebroadcaster{Bus: bus, Config: config, Repos: repos}
+}
+
+func (r *Rebroadcaster) interval() int64 {
+	return int64(r.Config.RebroadcastIntervalInSec) * 1000
+}
+
+func (r *Rebroadcaster) maxInterval() int64 {
+	return int64(r.Config.RebroadcastMaxIntervalInSec) * 1000
+}
+
+func (r *Rebroadcaster) expiry() int64 {
+	return int64(r.Config.TxExpiryInSec) * 1000
+}
+
+func (r *Rebroadcaster) process(submission sub.Submission, index *idx.Index, now int64) (sub.Submission, bool) {
+	switch {
+	case index.HasTx(submission.Tx.Id):
+		submission.Status = sub.Confirmed
+		utils.LogInfo("Transaction confirmed", submission.Id())
+	case submission.IsExpired(now, r.expiry()):
+		submission.Status = sub.Dropped
+		utils.LogInfo("Transaction expired", submission.Id())
+
+		if err := r.Repos.BlockchainRepo.RemovePendingTx(submission.Tx); err != nil {
+			utils.LogError("Failed to remove expired transaction", submission.Id(), err.Error())
+		}
+	case submission.IsDue(now):
+		r.broadcast(submission.Tx)
+		submission.Broadcasted(now, r.interval(), r.maxInterval())
+	default:
+		return submission, false
+	}
+
+	return submission, true
+}
+
+func (r *Rebroadcaster) broadcast(tx bc.Transaction) {
+	nodes, err := r.Repos.NodeRepo.GetNodes()
+	if err != nil {
+		utils.LogError("Failed to load nodes", err.Error())
+		return
+	}
+
+	responses := node_client.ShareTx(nodes, tx)
+
+	if responses.HasConnectionRefused() {
+		r.Bus.Handle(eventbus.DataEvent{Ev: events.ConnectionRefusedEvent})
+	}
+
+	if responses.ErrorsRatio() > 0 {
+		utils.LogError("Failed to rebroadcast the transaction to some nodes", responses.Errors())
+	}
+}
+
+func (r *Rebroadcaster) rebroadcast() error {
+	submissions, err := r.Repos.SubmissionRepo.GetSubmissions()
+	if err != nil {
+		return utils.GenericError{Msg: "failed to load submissions", Extra: err}
+	}
+
+	pending := utils.Filter(submissions, func(s sub.Submission) bool {
+		return s.IsPending()
+	})
+	if len(pending) == 0 {
+		return nil
+	}
+
+	index, err := r.Repos.IndexRepo.GetIndex()
+	if err != nil {
+		return utils.GenericError{Msg: "index currently not available", Extra: err}
+	}
+
+	now := time.Now().UnixMilli()
+	for _, submission := range pending {
+		submission, changed := r.process(submission, index, now)
+		if !changed {
+			continue
+		}
+
+		if err := r.Repos.SubmissionRepo.UpdateSubmission(submission); err != nil {
+			utils.LogError("Failed to update submission", submission.Id(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *Rebroadcaster) Run() {
+	for {
+		err := r.rebroadcast()
+		if err != nil {
+			utils.LogError("Rebroadcast [FAIL]", err.Error())
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+}
@@ -0,0 +1,166 @@
+package snapshots
+
+import (
+	"time"
+
+	cfg "github.com/antavelos/blockchain/src/internal/cmd/node/config"
+	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
+	rep "github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// Snapshotter creates state snapshots every SnapshotInterval blocks and
+// restores the local blockchain from snapshots.
+type Snapshotter struct {
+	Config *cfg.Config
+	Repos  *rep.Repos
+}
+
+func NewSnapshotter(config *cfg.Config, repos *rep.Repos) *Snapshotter {
+	return &Snapshotter{Config: config, Repos: repos}
+}
+
+// Create saves a snapshot of the state after the block of the given height.
+func (s *Snapshotter) Create(height int64) (bc.Snapshot, error) {
+	blockchain, err := s.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return bc.Snapshot{}, utils.GenericError{Msg: "blockchain currently not available", Extra: err}
+	}
+
+	if height == 0 {
+		height = blockchain.LastBlock().Idx
+	}
+
+	snapshot, err := blockchain.NewSnapshot(height)
+	if err != nil {
+		return bc.Snapshot{}, err
+	}
+
+	err = s.Repos.SnapshotRepo.AddSnapshot(snapshot, s.Config.SnapshotsToKeep)
+	if err != nil {
+		return bc.Snapshot{}, utils.GenericError{Msg: "failed to save snapshot", Extra: err}
+	}
+
+	return snapshot, nil
+}
+
+func (s *Snapshotter) createDue() error {
+	blockchain, err := s.Repos.BlockchainRepo.GetBlockchain()
+	if err != nil {
+		return utils.GenericError{Msg: "blockchain currently not available", Extra: err}
+	}
+
+	interval := int64(s.Config.SnapshotInterval)
+	tip := blockchain.LastBlock().Idx
+	height := tip - tip%interval
+
+	// a pruned blockchain can't provide the state before its base state
+	if height == 0 || height < blockchain.BaseState.Height || height <= s.Repos.SnapshotRepo.LatestHeight() {
+		return nil
+	}
+
+	snapshot, err := s.Create(height)
+	if err != nil {
+		return err
+	}
+
+	utils.LogInfo("New snapshot [OK]", snapshot.Height)
+
+	return nil
+}
+
+func (s *Snapshotter) Run() {
+	if s.Config.SnapshotInterval <= 0 {
+		return
+	}
+
+	for {
+		if err := s.createDue(); err != nil {
+			utils.LogError("New snapshot [FAIL]", err.Error())
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// Restore replaces the local blockchain with one built from the snapshot, the
+// headers up to it and the blocks after it, as provided by the first node
+// whose data verifies.
+func (s *Snapshotter) Restore(snapshot bc.Snapshot, nodes []nd.Node) error {
+	for _, node := range nodes {
+		blockchain, err := s.restoreFrom(node, snapshot)
+		if err != nil {
+			utils.LogError("Failed to restore snapshot", node.GetHost(), err.Error())
+			continue
+		}
+
+		local, _ := s.Repos.BlockchainRepo.GetBlockchain()
+		for _, tx := range local.TxPool {
+			if !blockchain.HasTx(tx) {
+				blockchain.TxPool = append(blockchain.TxPool, tx)
+			}
+		}
+
+		return s.Repos.BlockchainRepo.ReplaceBlockchain(*blockchain)
+	}
+
+	return utils.GenericError{Msg: "no node provided verifiable data for the snapshot"}
+}
+
+func (s *Snapshotter) restoreFrom(node nd.Node, snapshot bc.Snapshot) (*bc.Blockchain, error) {
+	headers, err := node_client.GetHeaders(node, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bc.ValidateHeaders(nil, headers, s.Config.Consensus)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := node_client.GetBlocks(node, snapshot.Height+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return bc.NewBlockchainFromSnapshot(s.Config.Consensus, headers, snapshot, blocks)
+}
+
+// SyncFromNodes restores the local blockchain from the most recent snapshot
+// offered by the known nodes.
+func (s *Snapshotter) SyncFromNodes() error {
+	nodes, err := s.Repos.NodeRepo.GetNodes()
+	if err != nil {
+		return utils.GenericError{Msg: "couldn't load nodes", Extra: err}
+	}
+
+	var latest bc.SnapshotInfo
+	var latestNode nd.Node
+	for _, node := range nodes {
+		infos, err := node_client.GetSnapshots(node)
+		if err != nil || len(infos) == 0 {
+			continue
+		}
+
+		if info := infos[len(infos)-1]; info.Height > latest.Height {
+			latest = info
+			latestNode = node
+		}
+	}
+
+	if latest.Height == 0 {
+		return utils.GenericError{Msg: "no snapshot available"}
+	}
+
+	snapshot, err := node_client.GetSnapshot(latestNode, latest.Height)
+	if err != nil {
+		return utils.GenericError{Msg: "failed to download snapshot", Extra: err}
+	}
+
+	// the node offering the snapshot is tried first
+	others := utils.Filter(nodes, func(n nd.Node) bool { return n.Name != latestNode.Name })
+
+	return s.Restore(snapshot, append([]nd.Node{latestNode}, others...))
+}
@@ -9,13 +9,19 @@ import (
 )
 
 const NewWalletEndpoint = "/wallets/new"
+const MultisigsEndpoint = "/multisigs"
+const MultisigEndpoint = "/multisigs/:address"
+const MultisigProposalsEndpoint = "/multisigs/:address/proposals"
+const ProposalEndpoint = "/proposals/:id"
+const ProposalSignaturesEndpoint = "/proposals/:id/signatures"
 
 type RouteHandler struct {
-	WalletRepo *repos.WalletRepo
+	WalletRepo   *repos.WalletRepo
+	MultisigRepo *repos.MultisigRepo
 }
 
-func NewRouteHandler(walletRepo *repos.WalletRepo) *RouteHandler {
-	return &RouteHandler{WalletRepo: walletRepo}
+func NewRouteHandler(walletRepo *repos.WalletRepo, multisigRepo *repos.MultisigRepo) *RouteHandler {
+	return &RouteHandler{WalletRepo: walletRepo, MultisigRepo: multisigRepo}
 }
 
 func (h RouteHandler) apiNewWallet(c *gin.Context) {
@@ -35,6 +41,11 @@ func (h *RouteHandler) InitRouter() *gin.Engine {
 	router.SetTrustedProxies([]string{"localhost", "127.0.0.1"})
 
 	router.GET(NewWalletEndpoint, h.apiNewWallet)
+	router.POST(MultisigsEndpoint, h.apiNewMultisig)
+	router.GET(MultisigEndpoint, h.apiGetMultisig)
+	router.POST(MultisigProposalsEndpoint, h.apiNewProposal)
+	router.GET(ProposalEndpoint, h.apiGetProposal)
+	router.POST(ProposalSignaturesEndpoint, h.apiAddSignature)
 
 	return router
 }
@@ -0,0 +1,128 @@
+package walletapi
+
+import (
+	"net/http"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/internal/pkg/repos"
+	"github.com/gin-gonic/gin"
+)
+
+type multisigInput struct {
+	Threshold  int      `json:"threshold"`
+	PublicKeys [][]byte `json:"publicKeys"`
+}
+
+type proposalInput struct {
+	Recipient string  `json:"recipient"`
+	Amount    float64 `json:"amount"`
+	Fee       float64 `json:"fee"`
+	Nonce     uint64  `json:"nonce"`
+}
+
+type signatureInput struct {
+	Signature string `json:"signature"`
+}
+
+// proposalOutput is a transaction spending from a multisig along with
+// whether it is signed by enough owners to be submitted to a node.
+type proposalOutput struct {
+	Tx       bc.Transaction `json:"tx"`
+	Complete bool           `json:"complete"`
+}
+
+func newProposalOutput(tx bc.Transaction) proposalOutput {
+	return proposalOutput{Tx: tx, Complete: tx.IsFullySigned()}
+}
+
+func (h RouteHandler) apiNewMultisig(c *gin.Context) {
+	var input multisigInput
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	multisig, err := h.MultisigRepo.CreateMultisig(input.Threshold, input.PublicKeys)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, multisig)
+}
+
+func (h RouteHandler) apiGetMultisig(c *gin.Context) {
+	multisig, err := h.MultisigRepo.GetMultisig(c.Param("address"))
+	if err == repos.ErrMultisigNotFound {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, multisig)
+}
+
+func (h RouteHandler) apiNewProposal(c *gin.Context) {
+	var input proposalInput
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	if !wallet.IsValidAddress(input.Recipient) || input.Amount <= 0 || input.Fee < 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	tx, err := h.MultisigRepo.Propose(c.Param("address"), input.Recipient, input.Amount, input.Fee, input.Nonce)
+	if err == repos.ErrMultisigNotFound {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, newProposalOutput(tx))
+}
+
+func (h RouteHandler) apiGetProposal(c *gin.Context) {
+	tx, err := h.MultisigRepo.GetProposal(c.Param("id"))
+	if err == repos.ErrProposalNotFound {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, newProposalOutput(tx))
+}
+
+// apiAddSignature collects the signature of one of the multisig's owners over
+// the proposed transaction's body.
+func (h RouteHandler) apiAddSignature(c *gin.Context) {
+	var input signatureInput
+	if err := c.BindJSON(&input); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	tx, err := h.MultisigRepo.AddSignature(c.Param("id"), input.Signature)
+	if err == repos.ErrProposalNotFound {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, newProposalOutput(tx))
+}
@@ -12,6 +12,8 @@ var envVars []string = []string{
 	"WALLETS_FILENAME",
 	"DNS_HOST",
 	"DNS_PORT",
+	"MULTISIGS_FILENAME",
+	"PROPOSALS_FILENAME",
 }
 
 type Config struct {
@@ -28,7 +28,9 @@ func main() {
 		go simulator.Run()
 	}
 
-	routeHandler := api.NewRouteHandler(walletRepo)
+	multisigRepo := repos.NewMultisigRepo(db.NewDB(config.Get("MULTISIGS_FILENAME")), db.NewDB(config.Get("PROPOSALS_FILENAME")))
+
+	routeHandler := api.NewRouteHandler(walletRepo, multisigRepo)
 	router := routeHandler.InitRouter()
 	router.Run(fmt.Sprintf(":%v", config.Get("PORT")))
 }
@@ -10,6 +10,7 @@ import (
 	dns_client "github.com/antavelos/blockchain/src/internal/pkg/clients/dns"
 	node_client "github.com/antavelos/blockchain/src/internal/pkg/clients/node"
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
 	w "github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
 	"github.com/antavelos/blockchain/src/internal/pkg/repos"
 	"github.com/antavelos/blockchain/src/pkg/utils"
@@ -39,13 +40,19 @@ func (s Simulator) Run() {
 		}
 
 		if i%s.Config.TransactionCreationIntervalInSec == 0 {
-			tx, err := s.createTransaction()
+			node, err := s.getRandomNode()
 			if err != nil {
 				utils.LogError("Failed to create new transaction", err.Error())
 				continue
 			}
 
-			sentTx, err := s.sendTransaction(tx)
+			tx, err := s.createTransaction(node)
+			if err != nil {
+				utils.LogError("Failed to create new transaction", err.Error())
+				continue
+			}
+
+			sentTx, err := node_client.SendTransaction(node, tx)
 			msg := fmt.Sprintf("Transaction from %v to %v", tx.Body.Sender, tx.Body.Recipient)
 			if err != nil {
 				utils.LogError(msg, "[FAIL]", err.Error())
@@ -84,7 +91,9 @@ func (s Simulator) getRandomWallets() ([]w.Wallet, error) {
 	return []w.Wallet{randomWallet1, randomWallet2}, nil
 }
 
-func (s Simulator) createTransaction() (bc.Transaction, error) {
+// createTransaction builds a transaction between random wallets signed with
+// the sender's next nonce according to the node.
+func (s Simulator) createTransaction(node nd.Node) (bc.Transaction, error) {
 	randomWallets, err := s.getRandomWallets()
 	if err != nil {
 		return bc.Transaction{}, err
@@ -92,26 +101,29 @@ func (s Simulator) createTransaction() (bc.Transaction, error) {
 	senderWallet := randomWallets[0]
 	recipientWallet := randomWallets[1]
 
-	return bc.NewTransaction(senderWallet, recipientWallet, utils.GetRandomFloat(0.001, 0.1))
+	balance, err := node_client.GetAddressBalance(node, senderWallet.AddressString())
+	if err != nil {
+		return bc.Transaction{}, utils.GenericError{Msg: "failed to retrieve the sender's nonce", Extra: err}
+	}
+
+	return bc.NewTransaction(senderWallet, recipientWallet, utils.GetRandomFloat(0.001, 0.1), balance.NextNonce)
 }
 
 func (s Simulator) getDNSHost() string {
 	return fmt.Sprintf("http://%v:%v", s.Config.Get("DNS_HOST"), s.Config.Get("DNS_PORT"))
 }
 
-func (s Simulator) sendTransaction(tx bc.Transaction) (bc.Transaction, error) {
+func (s Simulator) getRandomNode() (nd.Node, error) {
 	dnsHost := s.getDNSHost()
 
 	nodes, err := dns_client.GetDNSNodes(dnsHost)
 	if err != nil {
-		return tx, utils.GenericError{Msg: "failed to retrieve DNS nodes"}
+		return nd.Node{}, utils.GenericError{Msg: "failed to retrieve DNS nodes"}
 	}
 
 	if len(nodes) == 0 {
-		return tx, utils.GenericError{Msg: "nodes not available"}
+		return nd.Node{}, utils.GenericError{Msg: "nodes not available"}
 	}
 
-	randomNode := nodes[utils.GetRandomInt(len(nodes)-1)]
-
-	return node_client.SendTransaction(randomNode, tx)
+	return nodes[utils.GetRandomInt(len(nodes)-1)], nil
 }
@@ -1,7 +1,12 @@
 package clientnode
 
 import (
+	"encoding/json"
+	"fmt"
+
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	ex "github.com/antavelos/blockchain/src/internal/pkg/models/explorer"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/mining"
 	nd "github.com/antavelos/blockchain/src/internal/pkg/models/node"
 	"github.com/antavelos/blockchain/src/pkg/rest"
 )
@@ -11,6 +16,15 @@ const sharedBlocksEndpoint = "/shared-blocks"
 const pingEndpoint = "/ping"
 const blockchainEndpoint = "/blockchain"
 const transactionsEndpoint = "/transactions"
+const txPoolIdsEndpoint = "/tx-pool/ids"
+const txPoolTxsEndpoint = "/tx-pool/txs"
+const latestBlockEndpoint = "/v1/blocks/latest"
+const addressesEndpoint = "/v1/addresses"
+const headersEndpoint = "/headers"
+const stateEndpoint = "/state"
+const blocksEndpoint = "/blocks"
+const snapshotsEndpoint = "/snapshots"
+const miningEndpoint = "/mining"
 
 func ShareTx(nodes []nd.Node, tx bc.Transaction) rest.BulkResponse {
 	var requesters []rest.Requester
@@ -76,3 +90,274 @@ func SendTransaction(node nd.Node, tx bc.Transaction) (bc.Transaction, error) {
 
 	return bc.UnmarshalTransaction(response.Body)
 }
+
+func GetTxPoolIds(node nd.Node) ([]string, error) {
+	requester := rest.GetRequester{
+		URL: node.GetHost() + txPoolIdsEndpoint,
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	var ids []string
+	err := json.Unmarshal(response.Body, &ids)
+
+	return ids, err
+}
+
+func GetPoolTxs(node nd.Node, ids []string) ([]bc.Transaction, error) {
+	requester := rest.PostRequester{
+		URL:  node.GetHost() + txPoolTxsEndpoint,
+		Body: ids,
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	var txs []bc.Transaction
+	err := json.Unmarshal(response.Body, &txs)
+
+	return txs, err
+}
+
+func GetTransactionReceipt(node nd.Node, txId string) (bc.Receipt, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v", node.GetHost(), transactionsEndpoint, txId),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.Receipt{}, response.Err
+	}
+
+	var receipt bc.Receipt
+	err := json.Unmarshal(response.Body, &receipt)
+
+	return receipt, err
+}
+
+func WaitTransaction(node nd.Node, txId string, confirmations int) (bc.Receipt, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v/wait?confirmations=%v", node.GetHost(), transactionsEndpoint, txId, confirmations),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.Receipt{}, response.Err
+	}
+
+	var receipt bc.Receipt
+	err := json.Unmarshal(response.Body, &receipt)
+
+	return receipt, err
+}
+
+func GetLatestBlock(node nd.Node) (ex.Block, error) {
+	requester := rest.GetRequester{
+		URL: node.GetHost() + latestBlockEndpoint,
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return ex.Block{}, response.Err
+	}
+
+	var block ex.Block
+	err := json.Unmarshal(response.Body, &block)
+
+	return block, err
+}
+
+func GetAddressBalance(node nd.Node, address string) (ex.Balance, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v/balance", node.GetHost(), addressesEndpoint, address),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return ex.Balance{}, response.Err
+	}
+
+	var balance ex.Balance
+	err := json.Unmarshal(response.Body, &balance)
+
+	return balance, err
+}
+
+func GetAddressTxs(node nd.Node, address string, cursor string) (ex.Page[ex.Transaction], error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v/txs?cursor=%v", node.GetHost(), addressesEndpoint, address, cursor),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return ex.Page[ex.Transaction]{}, response.Err
+	}
+
+	var page ex.Page[ex.Transaction]
+	err := json.Unmarshal(response.Body, &page)
+
+	return page, err
+}
+
+func GetInclusionProof(node nd.Node, txId string) (bc.InclusionProof, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v/proof", node.GetHost(), transactionsEndpoint, txId),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.InclusionProof{}, response.Err
+	}
+
+	var proof bc.InclusionProof
+	err := json.Unmarshal(response.Body, &proof)
+
+	return proof, err
+}
+
+func GetHeaders(node nd.Node, from int64) ([]bc.BlockHeader, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v?from=%v", node.GetHost(), headersEndpoint, from),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	var headers []bc.BlockHeader
+	err := json.Unmarshal(response.Body, &headers)
+
+	return headers, err
+}
+
+func GetStateProof(node nd.Node, address string, height int64) (bc.StateProof, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v/proof?height=%v", node.GetHost(), stateEndpoint, address, height),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.StateProof{}, response.Err
+	}
+
+	var proof bc.StateProof
+	err := json.Unmarshal(response.Body, &proof)
+
+	return proof, err
+}
+
+func GetBlocks(node nd.Node, from int64) ([]bc.Block, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v?from=%v", node.GetHost(), blocksEndpoint, from),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	var blocks []bc.Block
+	err := json.Unmarshal(response.Body, &blocks)
+
+	return blocks, err
+}
+
+func GetSnapshots(node nd.Node) ([]bc.SnapshotInfo, error) {
+	requester := rest.GetRequester{
+		URL: node.GetHost() + snapshotsEndpoint,
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	var snapshots []bc.SnapshotInfo
+	err := json.Unmarshal(response.Body, &snapshots)
+
+	return snapshots, err
+}
+
+func GetSnapshot(node nd.Node, height int64) (bc.Snapshot, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/%v", node.GetHost(), snapshotsEndpoint, height),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.Snapshot{}, response.Err
+	}
+
+	return bc.UnmarshalSnapshot(response.Body)
+}
+
+func miningRequest(requester rest.Requester) (mining.Status, error) {
+	response := requester.Request()
+	if response.Err != nil {
+		return mining.Status{}, response.Err
+	}
+
+	return mining.UnmarshalStatus(response.Body)
+}
+
+func GetMiningStatus(node nd.Node) (mining.Status, error) {
+	return miningRequest(rest.GetRequester{URL: node.GetHost() + miningEndpoint})
+}
+
+func StartMining(node nd.Node) (mining.Status, error) {
+	return miningRequest(rest.PostRequester{URL: node.GetHost() + miningEndpoint + "/start"})
+}
+
+func StopMining(node nd.Node) (mining.Status, error) {
+	return miningRequest(rest.PostRequester{URL: node.GetHost() + miningEndpoint + "/stop"})
+}
+
+func SetMiningWorkers(node nd.Node, workers int) (mining.Status, error) {
+	return miningRequest(rest.PostRequester{
+		URL:  node.GetHost() + miningEndpoint + "/workers",
+		Body: map[string]int{"workers": workers},
+	})
+}
+
+func SetMiningRewardAddress(node nd.Node, address string) (mining.Status, error) {
+	return miningRequest(rest.PostRequester{
+		URL:  node.GetHost() + miningEndpoint + "/reward-address",
+		Body: map[string]string{"address": address},
+	})
+}
+
+func GetBlockTemplate(node nd.Node, rewardAddress string) (mining.BlockTemplate, error) {
+	requester := rest.GetRequester{
+		URL: fmt.Sprintf("%v%v/template?rewardAddress=%v", node.GetHost(), miningEndpoint, rewardAddress),
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return mining.BlockTemplate{}, response.Err
+	}
+
+	return mining.UnmarshalBlockTemplate(response.Body)
+}
+
+func SubmitBlock(node nd.Node, submission mining.BlockSubmission) (bc.Block, error) {
+	requester := rest.PostRequester{
+		URL:  node.GetHost() + miningEndpoint + "/submit",
+		Body: submission,
+	}
+
+	response := requester.Request()
+	if response.Err != nil {
+		return bc.Block{}, response.Err
+	}
+
+	var block bc.Block
+	err := json.Unmarshal(response.Body, &block)
+
+	return block, err
+}
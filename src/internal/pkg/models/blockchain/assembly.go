@@ -0,0 +1,284 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// UnlimitedBytes is the byte limit of blocks whose size is not bounded.
+const UnlimitedBytes = math.MaxInt
+
+// BlockLimits bound the number of transactions of a block and their total
+// size in bytes.
+type BlockLimits struct {
+	MaxTxs   int
+	MaxBytes int
+}
+
+// without returns the limits left after the transaction is included.
+func (l BlockLimits) without(tx Transaction) BlockLimits {
+	return BlockLimits{
+		MaxTxs:   nonNegative(l.MaxTxs - 1),
+		MaxBytes: nonNegative(l.MaxBytes - tx.Size()),
+	}
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Size is the size of the encoded transaction in bytes.
+func (tx Transaction) Size() int {
+	data, _ := json.Marshal(tx)
+	return len(data)
+}
+
+// FeeRate is the fee the transaction pays per byte.
+func (tx Transaction) FeeRate() float64 {
+	return tx.Body.Fee / float64(tx.Size())
+}
+
+// AssemblyPolicy selects the pending transactions that go in a new block.
+// The selected transactions are returned in the order they are included.
+type AssemblyPolicy interface {
+	SelectTxs(pool []Transaction, limits BlockLimits) []Transaction
+}
+
+var assemblyPolicies = map[string]AssemblyPolicy{
+	"fifo":     FIFOPolicy{},
+	"fee-rate": FeeRatePolicy{},
+	"ancestor": AncestorPolicy{},
+}
+
+// NewAssemblyPolicy returns the policy registered under the given name.
+func NewAssemblyPolicy(name string) (AssemblyPolicy, error) {
+	policy, ok := assemblyPolicies[name]
+	if !ok {
+		return nil, utils.GenericError{Msg: "unknown block assembly policy: " + name}
+	}
+
+	return policy, nil
+}
+
+// blockSpace keeps track of the room left in a block for the transactions of
+// a pool, referred to by their positions. Their sizes are computed once, as
+// encoding them is costly.
+type blockSpace struct {
+	limits BlockLimits
+	sizes  []int
+	txs    int
+	bytes  int
+}
+
+func newBlockSpace(pool []Transaction, limits BlockLimits) blockSpace {
+	return blockSpace{limits: limits, sizes: utils.Map(pool, Transaction.Size)}
+}
+
+func (s blockSpace) fits(txs ...int) bool {
+	bytes := 0
+	for _, i := range txs {
+		bytes += s.sizes[i]
+	}
+
+	return s.txs+len(txs) <= s.limits.MaxTxs && bytes <= s.limits.MaxBytes-s.bytes
+}
+
+func (s *blockSpace) add(txs ...int) {
+	for _, i := range txs {
+		s.txs++
+		s.bytes += s.sizes[i]
+	}
+}
+
+// feeRate is the fee the transactions pay per byte overall.
+func (s blockSpace) feeRate(pool []Transaction, txs ...int) float64 {
+	fees, size := 0.0, 0
+	for _, i := range txs {
+		fees += pool[i].Body.Fee
+		size += s.sizes[i]
+	}
+
+	return fees / float64(size)
+}
+
+func (s blockSpace) isFull() bool {
+	return s.txs >= s.limits.MaxTxs
+}
+
+// FIFOPolicy selects the transactions in pool order. Once a transaction does
+// not fit, the later ones of the same sender are skipped too.
+type FIFOPolicy struct{}
+
+func (FIFOPolicy) SelectTxs(pool []Transaction, limits BlockLimits) []Transaction {
+	space := newBlockSpace(pool, limits)
+	skipped := make(map[string]bool)
+	selected := []Transaction{}
+
+	for i, tx := range pool {
+		if space.isFull() {
+			break
+		}
+
+		if skipped[tx.Body.Sender] || !space.fits(i) {
+			skipped[tx.Body.Sender] = true
+			continue
+		}
+
+		space.add(i)
+		selected = append(selected, tx)
+	}
+
+	return selected
+}
+
+// FeeRatePolicy selects the transactions with the highest fee rate first while
+// keeping the transactions of every sender in pool order.
+type FeeRatePolicy struct{}
+
+func (FeeRatePolicy) SelectTxs(pool []Transaction, limits BlockLimits) []Transaction {
+	space := newBlockSpace(pool, limits)
+
+	// the positions of the transactions of every sender in pool order
+	queues := make(map[string][]int)
+	for i, tx := range pool {
+		queues[tx.Body.Sender] = append(queues[tx.Body.Sender], i)
+	}
+
+	selected := []Transaction{}
+	for !space.isFull() {
+		best := ""
+		for sender, queue := range queues {
+			if best == "" || space.isPreferred(pool, queue[0], queues[best][0]) {
+				best = sender
+			}
+		}
+
+		if best == "" {
+			break
+		}
+
+		i := queues[best][0]
+		if !space.fits(i) {
+			delete(queues, best)
+			continue
+		}
+
+		space.add(i)
+		selected = append(selected, pool[i])
+
+		if queues[best] = queues[best][1:]; len(queues[best]) == 0 {
+			delete(queues, best)
+		}
+	}
+
+	return selected
+}
+
+// isPreferred compares the transactions of the given positions by fee rate,
+// falling back to pool order.
+func (s blockSpace) isPreferred(pool []Transaction, i int, j int) bool {
+	if rate, other := s.feeRate(pool, i), s.feeRate(pool, j); rate != other {
+		return rate > other
+	}
+
+	return i < j
+}
+
+// AncestorPolicy selects packages of transactions along with their pending
+// ancestors, i.e. the earlier transactions of the same sender and those
+// funding the sender, by their overall fee rate. A transaction is therefore
+// never included before the transactions it depends on, and a low fee
+// transaction gets included when a descendant pays for it.
+type AncestorPolicy struct{}
+
+func (AncestorPolicy) SelectTxs(pool []Transaction, limits BlockLimits) []Transaction {
+	space := newBlockSpace(pool, limits)
+	parents := txParents(pool)
+	included := make([]bool, len(pool))
+
+	selected := []Transaction{}
+	for !space.isFull() {
+		var best []int
+		bestRate := -1.0
+
+		for i := range pool {
+			if included[i] {
+				continue
+			}
+
+			pkg := txPackage(i, parents, included)
+			if !space.fits(pkg...) {
+				continue
+			}
+
+			if rate := space.feeRate(pool, pkg...); rate > bestRate {
+				best, bestRate = pkg, rate
+			}
+		}
+
+		if best == nil {
+			break
+		}
+
+		for _, i := range best {
+			included[i] = true
+			space.add(i)
+			selected = append(selected, pool[i])
+		}
+	}
+
+	return selected
+}
+
+// txParents returns for every transaction of the pool the positions of the
+// earlier transactions it directly depends on.
+func txParents(pool []Transaction) [][]int {
+	parents := make([][]int, len(pool))
+
+	for i, tx := range pool {
+		if tx.isCoinbase() {
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if pool[j].Body.Sender == tx.Body.Sender || pool[j].Body.Recipient == tx.Body.Sender {
+				parents[i] = append(parents[i], j)
+			}
+		}
+	}
+
+	return parents
+}
+
+// txPackage returns in pool order the positions of the transaction and its
+// ancestors that are not included yet.
+func txPackage(i int, parents [][]int, included []bool) []int {
+	visited := map[int]bool{i: true}
+	stack := []int{i}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, parent := range parents[current] {
+			if !visited[parent] && !included[parent] {
+				visited[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+
+	pkg := make([]int, 0, len(visited))
+	for j := range visited {
+		pkg = append(pkg, j)
+	}
+	sort.Ints(pkg)
+
+	return pkg
+}
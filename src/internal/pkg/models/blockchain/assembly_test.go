@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+)
+
+func newFeeTx(id string, sender string, recipient string, fee float64) Transaction {
+	return Transaction{Id: id, Body: TransactionBody{Sender: sender, Recipient: recipient, Amount: 1.0, Fee: fee}}
+}
+
+func txIds(txs []Transaction) []string {
+	ids := []string{}
+	for _, tx := range txs {
+		ids = append(ids, tx.Id)
+	}
+	return ids
+}
+
+func TestFIFOPolicy(t *testing.T) {
+	pool := []Transaction{
+		newFeeTx("tx1", "John", "Jane", 0),
+		newFeeTx("tx2", "Jane", "John", 0),
+		newFeeTx("tx3", "Jack", "John", 0),
+	}
+
+	selected := FIFOPolicy{}.SelectTxs(pool, BlockLimits{MaxTxs: 2, MaxBytes: UnlimitedBytes})
+	if ids := txIds(selected); !reflect.DeepEqual(ids, []string{"tx1", "tx2"}) {
+		t.Errorf("Expected the first transactions but got %v", ids)
+	}
+
+	// once a sender's transaction does not fit its later ones are skipped
+	pool = []Transaction{
+		{Id: "tx1", Body: TransactionBody{Sender: "John", Recipient: "Jane with a long name that does not fit", Amount: 1.0}},
+		newFeeTx("tx2", "John", "Jane", 0),
+		newFeeTx("tx3", "Jack", "Jane", 0),
+	}
+
+	limits := BlockLimits{MaxTxs: 3, MaxBytes: pool[2].Size()}
+	if ids := txIds(FIFOPolicy{}.SelectTxs(pool, limits)); !reflect.DeepEqual(ids, []string{"tx3"}) {
+		t.Errorf("Expected the later transactions of the sender to be skipped but got %v", ids)
+	}
+}
+
+func TestFeeRatePolicy(t *testing.T) {
+	pool := []Transaction{
+		newFeeTx("tx1", "John", "Jane", 0.1),
+		newFeeTx("tx2", "John", "Jane", 0.9),
+		newFeeTx("tx3", "Jack", "Jane", 0.5),
+		newFeeTx("tx4", "Jill", "Jane", 0.2),
+	}
+
+	selected := FeeRatePolicy{}.SelectTxs(pool, BlockLimits{MaxTxs: 3, MaxBytes: UnlimitedBytes})
+
+	// tx2 pays the most but cannot precede tx1 of the same sender
+	if ids := txIds(selected); !reflect.DeepEqual(ids, []string{"tx3", "tx4", "tx1"}) {
+		t.Errorf("Expected transactions by fee rate in sender order but got %v", ids)
+	}
+}
+
+func TestAncestorPolicy(t *testing.T) {
+	pool := []Transaction{
+		newFeeTx("tx1", "John", "Jane", 0),
+		newFeeTx("tx2", "Jack", "Jill", 0.3),
+		newFeeTx("tx3", "Jane", "Jack", 1.0),
+		newFeeTx("tx4", "John", "Jill", 0),
+	}
+
+	selected := AncestorPolicy{}.SelectTxs(pool, BlockLimits{MaxTxs: 3, MaxBytes: UnlimitedBytes})
+
+	// tx3 is funded by tx1 and pays for both, while tx4 depends on tx1 only
+	if ids := txIds(selected); !reflect.DeepEqual(ids, []string{"tx1", "tx3", "tx2"}) {
+		t.Errorf("Expected the package of tx3 first but got %v", ids)
+	}
+
+	// a package that does not fit is not split
+	selected = AncestorPolicy{}.SelectTxs(pool, BlockLimits{MaxTxs: 1, MaxBytes: UnlimitedBytes})
+	if ids := txIds(selected); !reflect.DeepEqual(ids, []string{"tx2"}) {
+		t.Errorf("Expected the best fitting transaction but got %v", ids)
+	}
+}
+
+func TestAssembleBlockWithCoinbase(t *testing.T) {
+	blockchain := NewBlockchain()
+	blockchain.TxPool = []Transaction{
+		{Id: "tx1", Body: TransactionBody{Sender: "0", Recipient: "John", Amount: 5.0}},
+		{Id: "tx2", Body: TransactionBody{Sender: "0", Recipient: "Jane", Amount: 5.0}},
+	}
+	coinbase := []Transaction{{Id: "cb", Body: TransactionBody{Sender: "0", Recipient: "Jack", Amount: 1.0}}}
+
+	block, err := blockchain.AssembleBlock(nil, FIFOPolicy{}, BlockLimits{MaxTxs: 2, MaxBytes: UnlimitedBytes}, coinbase)
+	if err != nil {
+		t.Fatalf("Expected block but got: %v", err)
+	}
+
+	if ids := txIds(block.Txs); !reflect.DeepEqual(ids, []string{"cb", "tx1"}) {
+		t.Errorf("Expected the coinbase to count against the limits but got %v", ids)
+	}
+
+	if err := blockchain.AddBlock(block); err != nil {
+		t.Errorf("Expected block to be added but got: %v", err)
+	}
+}
+
+// newFundedBlockchain returns a blockchain whose first block credits the
+// wallets with the given amount each.
+func newFundedBlockchain(t *testing.T, amount float64, wallets ...*wallet.Wallet) *Blockchain {
+	blockchain := NewBlockchain()
+	for i, w := range wallets {
+		blockchain.TxPool = append(blockchain.TxPool, Transaction{
+			Id:   fmt.Sprintf("funding%v", i),
+			Body: TransactionBody{Sender: "0", Recipient: w.AddressString(), Amount: amount},
+		})
+	}
+
+	block, _ := blockchain.NewBlock(len(wallets))
+	if err := blockchain.AddBlock(block); err != nil {
+		t.Fatalf("Expected block to be added but got: %v", err)
+	}
+
+	return blockchain
+}
+
+func TestAddTxRefusesUnsignedTransactions(t *testing.T) {
+	john, _ := wallet.NewWallet()
+	jane, _ := wallet.NewWallet()
+	blockchain := newFundedBlockchain(t, 10, john, jane)
+
+	unsigned := Transaction{Id: "unsigned", Body: TransactionBody{Sender: john.AddressString(), Recipient: jane.AddressString(), Amount: 1}}
+	if _, err := blockchain.AddTx(nil, unsigned); err == nil {
+		t.Errorf("Expected an unsigned transaction to be refused but got %v", err)
+	}
+
+	forged, _ := NewTransaction(*jane, *jane, 1, 0)
+	forged.Id = "forged"
+	forged.Body.Sender = john.AddressString()
+	if _, err := blockchain.AddTx(nil, forged); err == nil {
+		t.Errorf("Expected a forged transaction to be refused but got %v", err)
+	}
+
+	signed, _ := NewTransaction(*john, *jane, 1, 0)
+	signed.Id = "signed"
+	if _, err := blockchain.AddTx(nil, signed); err != nil {
+		t.Errorf("Expected a signed transaction to be added but got %v", err)
+	}
+
+	if ids := txIds(blockchain.TxPool); !reflect.DeepEqual(ids, []string{"signed"}) {
+		t.Errorf("Expected only the signed transaction to be pending but got %v", ids)
+	}
+}
+
+func TestAssemblySkipsAndDropsInvalidTxs(t *testing.T) {
+	john, _ := wallet.NewWallet()
+	jane, _ := wallet.NewWallet()
+	blockchain := newFundedBlockchain(t, 10, john, jane)
+
+	forged, _ := NewTransaction(*jane, *jane, 1, 0)
+	forged.Id = "forged"
+	forged.Body.Sender = john.AddressString()
+	signed, _ := NewTransaction(*jane, *john, 1, 0)
+	signed.Id = "signed"
+	blockchain.TxPool = []Transaction{forged, signed}
+
+	block, err := blockchain.AssembleBlock(nil, FIFOPolicy{}, BlockLimits{MaxTxs: 10, MaxBytes: UnlimitedBytes}, nil)
+	if err != nil {
+		t.Fatalf("Expected block but got: %v", err)
+	}
+
+	if ids := txIds(block.Txs); !reflect.DeepEqual(ids, []string{"signed"}) {
+		t.Errorf("Expected the forged transaction to be left out but got %v", ids)
+	}
+
+	if err := blockchain.AddBlock(block); err != nil {
+		t.Errorf("Expected block to be added but got: %v", err)
+	}
+
+	if dropped := txIds(blockchain.DropInvalidTxs(nil)); !reflect.DeepEqual(dropped, []string{"forged"}) {
+		t.Errorf("Expected the forged transaction to be dropped but got %v", dropped)
+	}
+
+	if len(blockchain.TxPool) != 0 {
+		t.Errorf("Expected an empty pool but got %v", txIds(blockchain.TxPool))
+	}
+}
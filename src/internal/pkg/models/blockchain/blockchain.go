@@ -2,8 +2,10 @@ package blockchain
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -12,38 +14,86 @@ import (
 	"github.com/antavelos/blockchain/src/pkg/utils"
 )
 
+// TransactionBody is what the sender signs. The optional Fee is paid by the
+// sender on top of the Amount and gets burnt; it prioritizes the transaction
+// in block assembly. Transfers have no Type; governance and staking
+// transactions have one of their types. A slashing transaction carries the
+// Evidence against its Recipient. The Nonce is the number of the sender's
+// transactions included before it, so that a signed transaction cannot be
+// included again.
 type TransactionBody struct {
-	Sender    string  `json:"sender"`
-	Recipient string  `json:"recipient"`
-	Amount    float64 `json:"amount"`
+	Sender    string              `json:"sender"`
+	Recipient string              `json:"recipient"`
+	Amount    float64             `json:"amount"`
+	Fee       float64             `json:"fee,omitempty"`
+	Type      string              `json:"type,omitempty"`
+	Evidence  *DoubleSignEvidence `json:"evidence,omitempty"`
+	Nonce     uint64              `json:"nonce,omitempty"`
 }
 
-func (txb TransactionBody) getBalanceForAddress(address string) float64 {
-	switch address {
-	case txb.Recipient:
-		return txb.Amount
-	case txb.Sender:
-		return -txb.Amount
+// debit is what the transaction takes from the sender's balance.
+func (txb TransactionBody) debit() float64 {
+	switch txb.Type {
+	case "", StakeTx:
+		return txb.Amount + txb.Fee
 	default:
-		return 0.0
+		return txb.Fee
 	}
 }
 
+// credit returns the address the transaction adds the amount to, if any.
+func (txb TransactionBody) credit() (string, bool) {
+	switch txb.Type {
+	case "":
+		return txb.Recipient, true
+	case WithdrawTx:
+		return txb.Sender, true
+	default:
+		return "", false
+	}
+}
+
+func (txb TransactionBody) getBalanceForAddress(address string) float64 {
+	balance := 0.0
+
+	if address == txb.Sender {
+		balance -= txb.debit()
+	}
+
+	if credited, ok := txb.credit(); ok && address == credited {
+		balance += txb.Amount
+	}
+
+	return balance
+}
+
+// Transaction is signed by its sender or, when spending from a Multisig, by
+// the Signatures of enough of the multisig's owners.
 type Transaction struct {
-	Id        string          `json:"id"`
-	Timestamp int64           `json:"timestamp"`
-	Body      TransactionBody `json:"body"`
-	Signature string          `json:"signature"`
+	Id         string           `json:"id"`
+	Timestamp  int64            `json:"timestamp"`
+	Body       TransactionBody  `json:"body"`
+	Signature  string           `json:"signature"`
+	Multisig   *wallet.Multisig `json:"multisig,omitempty"`
+	Signatures []string         `json:"signatures,omitempty"`
 }
 
-func NewTransaction(senderWallet wallet.Wallet, recipientWallet wallet.Wallet, amount float64) (Transaction, error) {
+func NewTransaction(senderWallet wallet.Wallet, recipientWallet wallet.Wallet, amount float64, nonce uint64) (Transaction, error) {
+	return NewTransactionTo(senderWallet, recipientWallet.AddressString(), amount, nonce)
+}
 
-	txb := TransactionBody{
+// NewTransactionTo builds a transaction signed by the sender's wallet towards
+// the given recipient address.
+func NewTransactionTo(senderWallet wallet.Wallet, recipient string, amount float64, nonce uint64) (Transaction, error) {
+	return signTransaction(senderWallet, TransactionBody{
 		Sender:    senderWallet.AddressString(),
-		Recipient: recipientWallet.AddressString(),
+		Recipient: recipient,
 		Amount:    amount,
-	}
+		Nonce:     nonce,
+	})
+}
 
+func signTransaction(senderWallet wallet.Wallet, txb TransactionBody) (Transaction, error) {
 	txbBytes, err := json.Marshal(txb)
 	if err != nil {
 		return Transaction{}, utils.GenericError{Msg: "failed to marshal transaction body", Extra: err}
@@ -60,6 +110,12 @@ func NewTransaction(senderWallet wallet.Wallet, recipientWallet wallet.Wallet, a
 	}, nil
 }
 
+// BalanceChange returns the amount by which the transaction changes the balance
+// of the given address.
+func (tx Transaction) BalanceChange(address string) float64 {
+	return tx.Body.getBalanceForAddress(address)
+}
+
 func (tx Transaction) isCoinbase() bool {
 	return tx.Body.Sender == "0"
 }
@@ -74,44 +130,138 @@ func (tx Transaction) Validate() error {
 		return utils.GenericError{Msg: "failed to marshal transaction body"}
 	}
 
-	signatureBytes, err := hex.DecodeString(tx.Signature)
+	if tx.Multisig != nil {
+		return tx.validateMultisig(txBodyBytes)
+	}
+
+	signer, err := recoverSigner(txBodyBytes, tx.Signature)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(signer, tx.Body.Sender) {
+		return utils.GenericError{Msg: "sender address does not match with the public key of the signature"}
+	}
+
+	return nil
+}
+
+// recoverSigner returns the address of the key the data got signed with.
+func recoverSigner(data []byte, signature string) (string, error) {
+	signatureBytes, err := hex.DecodeString(signature)
 	if err != nil {
-		return utils.GenericError{Msg: "failed to decode signature"}
+		return "", utils.GenericError{Msg: "failed to decode signature"}
 	}
 
-	publicKeyBytes, err := crypto.PublicKeyFromSignature(txBodyBytes, signatureBytes)
+	publicKeyBytes, err := crypto.PublicKeyFromSignature(data, signatureBytes)
 	if err != nil {
-		return utils.GenericError{Msg: "failed to retrieve public key from signature"}
+		return "", utils.GenericError{Msg: "failed to retrieve public key from signature"}
 	}
 
 	publicKey, err := crypto.UnmarshalPublicKey(publicKeyBytes)
 	if err != nil {
-		return utils.GenericError{Msg: "failed to unmarshal public key"}
+		return "", utils.GenericError{Msg: "failed to unmarshal public key"}
 	}
 
-	senderBytes, err := hex.DecodeString(tx.Body.Sender)
-	if err != nil {
-		return utils.GenericError{Msg: "failed to decode sender"}
+	if !crypto.VerifySignature(data, publicKeyBytes, signatureBytes) {
+		return "", utils.GenericError{Msg: "failed to verify signature"}
 	}
 
-	senderAddress := crypto.AddressFromPublicKey(publicKey)
-	if !bytes.Equal(senderAddress, senderBytes) {
-		return utils.GenericError{Msg: "sender address does not match with the public key of the signature"}
+	return hex.EncodeToString(crypto.AddressFromPublicKey(publicKey)), nil
+}
+
+// verifyNonce checks that the transaction is the next one of its sender on
+// top of the given state.
+func verifyNonce(state State, tx Transaction) error {
+	if tx.isCoinbase() {
+		return nil
 	}
 
-	if !crypto.VerifySignature(txBodyBytes, publicKeyBytes, signatureBytes) {
-		return utils.GenericError{Msg: "failed to verify signature"}
+	if expected := state.GetAccount(tx.Body.Sender).Nonce; tx.Body.Nonce != expected {
+		return utils.GenericError{Msg: fmt.Sprintf("expected nonce %v but got %v", expected, tx.Body.Nonce)}
 	}
 
 	return nil
 }
 
+// BlockHeader holds everything the hash of a block is computed from. The
+// transactions are committed through the MerkleRoot and the account state
+// after the block through the StateRoot. The Signature of engines sealing by
+// signature signs the hash and is not part of it, nor is the Reveal proof of
+// stake seeds the pick of the next proposer with.
+type BlockHeader struct {
+	Idx        int64  `json:"idx"`
+	Timestamp  int64  `json:"timestamp"`
+	PrevHash   []byte `json:"prevHash"`
+	MerkleRoot []byte `json:"merkleRoot"`
+	StateRoot  []byte `json:"stateRoot"`
+	Nonce      int64  `json:"nonce"`
+	Signature  string `json:"signature,omitempty"`
+	Reveal     string `json:"reveal,omitempty"`
+}
+
+// The header is hashed in a fixed-size binary form: the index and the
+// timestamp, the previous hash, the Merkle and state roots, each padded to
+// HashSize bytes, and finally the nonce at HeaderNonceOffset.
+const HashSize = 32
+const HeaderNonceOffset = 2*8 + 3*HashSize
+const HeaderSize = HeaderNonceOffset + 8
+
+func (h BlockHeader) IsValid(difficulty int) bool {
+	return IsValidHash(h.Hash(), difficulty)
+}
+
+// IsValidHash checks that the hash satisfies the proof of work of the given
+// difficulty.
+func IsValidHash(hash []byte, difficulty int) bool {
+	return bytes.HasPrefix(hash, DifficultyTarget(difficulty))
+}
+
+// DifficultyTarget returns the prefix the hash of a valid header starts with.
+func DifficultyTarget(difficulty int) []byte {
+	return []byte(strings.Repeat("0", difficulty))
+}
+
+// Bytes returns the fixed-size binary form of the header the hash is
+// computed from.
+func (h BlockHeader) Bytes() []byte {
+	data := make([]byte, HeaderSize)
+
+	binary.BigEndian.PutUint64(data[0:8], uint64(h.Idx))
+	binary.BigEndian.PutUint64(data[8:16], uint64(h.Timestamp))
+	copy(data[16:16+HashSize], h.PrevHash)
+	copy(data[16+HashSize:16+2*HashSize], h.MerkleRoot)
+	copy(data[16+2*HashSize:HeaderNonceOffset], h.StateRoot)
+	PutHeaderNonce(data, h.Nonce)
+
+	return data
+}
+
+// PutHeaderNonce sets the nonce of a header in its binary form so that miners
+// can try nonces without encoding the whole header again.
+func PutHeaderNonce(data []byte, nonce int64) {
+	binary.BigEndian.PutUint64(data[HeaderNonceOffset:HeaderSize], uint64(nonce))
+}
+
+func (h BlockHeader) Hash() []byte {
+	return crypto.HashData(h.Bytes())
+}
+
+func (h BlockHeader) HashString() string {
+	return hex.EncodeToString(h.Hash())
+}
+
+// Block holds the header and the transactions of a block. A pruned block
+// keeps its header only.
 type Block struct {
-	Idx       int64         `json:"idx"`
-	Timestamp int64         `json:"timestamp"`
-	Txs       []Transaction `json:"txs"`
-	PrevHash  []byte        `json:"prevHash"`
-	Nonce     int64         `json:"nonce"`
+	BlockHeader
+	Txs    []Transaction `json:"txs"`
+	Pruned bool          `json:"pruned,omitempty"`
+}
+
+func (b *Block) Prune() {
+	b.Txs = nil
+	b.Pruned = true
 }
 
 func (b *Block) HasTx(tx Transaction) bool {
@@ -123,23 +273,27 @@ func (b *Block) HasTx(tx Transaction) bool {
 	return false
 }
 
-func (b *Block) IsValid(difficulty int) bool {
-	hashedBlock := b.hash()
-
-	prefix := []byte(strings.Repeat("0", difficulty))
-
-	return bytes.Equal(hashedBlock[:difficulty], prefix)
+func (b *Block) GetTx(txId string) (Transaction, bool) {
+	for _, tx := range b.Txs {
+		if tx.Id == txId {
+			return tx, true
+		}
+	}
+	return Transaction{}, false
 }
 
-func (b Block) hash() []byte {
-	blockBytes, _ := json.Marshal(b)
-
-	return crypto.HashData(blockBytes)
+func (b *Block) HasValidMerkleRoot() bool {
+	return bytes.Equal(b.MerkleRoot, MerkleRoot(b.Txs))
 }
 
+// Blockchain keeps the blocks, the pending transactions and the state after
+// the last block. BaseState is the state the blocks following the pruned ones
+// are applied on.
 type Blockchain struct {
-	Blocks []Block       `json:"block"`
-	TxPool []Transaction `json:"txPool"`
+	Blocks    []Block       `json:"block"`
+	TxPool    []Transaction `json:"txPool"`
+	State     State         `json:"state"`
+	BaseState State         `json:"baseState"`
 }
 
 func NewBlockchain() *Blockchain {
@@ -151,6 +305,14 @@ func NewBlockchain() *Blockchain {
 
 func UnmarshalBlockchain(data []byte) (blockchain Blockchain, err error) {
 	err = json.Unmarshal(data, &blockchain)
+	if err != nil {
+		return
+	}
+
+	if blockchain.State.Height != blockchain.lastBlock().Idx {
+		blockchain.State, _ = blockchain.GetState(blockchain.lastBlock().Idx)
+	}
+
 	return
 }
 
@@ -168,6 +330,10 @@ func (bc *Blockchain) removeTx(tx Transaction) {
 	}
 }
 
+func (bc *Blockchain) RemovePendingTx(tx Transaction) {
+	bc.removeTx(tx)
+}
+
 func (bc *Blockchain) removeTxs(txs []Transaction) {
 	for _, tx := range txs {
 		bc.removeTx(tx)
@@ -179,7 +345,18 @@ func (bc *Blockchain) AddBlock(block Block) error {
 		return utils.GenericError{Msg: "block.PrevHash does not match with last block's hash"}
 	}
 
+	if !block.HasValidMerkleRoot() {
+		return utils.GenericError{Msg: "block.MerkleRoot does not match with block's transactions"}
+	}
+
+	state := bc.State.Copy()
+	state.ApplyBlock(block)
+	if !bytes.Equal(block.StateRoot, state.Root()) {
+		return utils.GenericError{Msg: "block.StateRoot does not match with the state after the block"}
+	}
+
 	bc.Blocks = append(bc.Blocks, block)
+	bc.State = state
 	bc.removeTxs(block.Txs)
 
 	return nil
@@ -187,18 +364,30 @@ func (bc *Blockchain) AddBlock(block Block) error {
 
 func (bc *Blockchain) createGenesisBlock() {
 	genesisBlock := Block{
-		Idx:       1,
-		Timestamp: time.Now().UnixMilli(),
-		PrevHash:  []byte{},
-		Nonce:     0,
+		BlockHeader: BlockHeader{
+			Idx:        1,
+			Timestamp:  time.Now().UnixMilli(),
+			PrevHash:   []byte{},
+			MerkleRoot: MerkleRoot(nil),
+			StateRoot:  NewState().Root(),
+			Nonce:      0,
+		},
 	}
 
 	bc.Blocks = append(bc.Blocks, genesisBlock)
+	bc.State = NewState()
+	bc.State.ApplyBlock(genesisBlock)
 }
 
-func (bc *Blockchain) AddTx(tx Transaction) (Transaction, error) {
-	if !bc.verifyTxSenderBalance(tx) {
-		return Transaction{}, utils.GenericError{Msg: "sender has not sufficient funds"}
+// AddTx adds the transaction to the pool once it verifies on top of the
+// pending transactions and, if given, against the rules of the consensus.
+func (bc *Blockchain) AddTx(consensus Consensus, tx Transaction) (Transaction, error) {
+	if bc.HasTx(tx) {
+		return Transaction{}, utils.GenericError{Msg: "transaction already exists"}
+	}
+
+	if err := verifyPendingTx(consensus, bc.PendingState(), tx); err != nil {
+		return Transaction{}, err
 	}
 
 	bc.TxPool = append(bc.TxPool, tx)
@@ -206,72 +395,286 @@ func (bc *Blockchain) AddTx(tx Transaction) (Transaction, error) {
 	return tx, nil
 }
 
+// verifyPendingTx checks that the transaction can be included in a block on
+// top of the given state: it has to be signed by its sender, who has to
+// afford it, and to follow the rules of the consensus, if any.
+func verifyPendingTx(consensus Consensus, state State, tx Transaction) error {
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+
+	if err := verifyNonce(state, tx); err != nil {
+		return err
+	}
+
+	if tx.Body.Fee < 0 {
+		return utils.GenericError{Msg: "transaction fee cannot be negative"}
+	}
+
+	if err := validateTxType(tx); err != nil {
+		return err
+	}
+
+	if !tx.isCoinbase() && tx.Body.debit() > state.GetAccount(tx.Body.Sender).Balance {
+		return utils.GenericError{Msg: "sender has not sufficient funds"}
+	}
+
+	if consensus != nil {
+		return consensus.VerifyTx(state, tx)
+	}
+
+	return nil
+}
+
+// NextNonce is the nonce of the next transaction of the address on top of its
+// pending ones.
+func (bc *Blockchain) NextNonce(address string) uint64 {
+	return bc.PendingState().GetAccount(address).Nonce
+}
+
+// PendingState is the state after the last block with the pending
+// transactions applied in pool order.
+func (bc *Blockchain) PendingState() State {
+	state := bc.State.Copy()
+	for _, tx := range bc.TxPool {
+		state.ApplyTx(tx)
+	}
+
+	return state
+}
+
+// DropInvalidTxs removes from the pool the transactions that no longer verify
+// on top of the last block and the pending transactions preceding them, and
+// returns them.
+func (bc *Blockchain) DropInvalidTxs(consensus Consensus) []Transaction {
+	state := bc.State.Copy()
+	valid := []Transaction{}
+	dropped := []Transaction{}
+
+	for _, tx := range bc.TxPool {
+		if err := verifyPendingTx(consensus, state, tx); err != nil {
+			dropped = append(dropped, tx)
+			continue
+		}
+
+		state.ApplyTx(tx)
+		valid = append(valid, tx)
+	}
+	bc.TxPool = valid
+
+	return dropped
+}
+
+func (bc *Blockchain) HasTx(tx Transaction) bool {
+	return bc.hasPendingTx(tx) || bc.hasConfirmedTx(tx)
+}
+
+// hasConfirmedTx walks the blocks for the transaction. Nodes look confirmed
+// transactions up in their index instead.
+func (bc *Blockchain) hasConfirmedTx(tx Transaction) bool {
+	for _, block := range bc.Blocks {
+		if block.HasTx(tx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bc *Blockchain) GetPendingTx(txId string) (Transaction, bool) {
+	for _, tx := range bc.TxPool {
+		if tx.Id == txId {
+			return tx, true
+		}
+	}
+	return Transaction{}, false
+}
+
+func (bc *Blockchain) hasPendingTx(tx Transaction) bool {
+	for _, poolTx := range bc.TxPool {
+		if tx.Id == poolTx.Id {
+			return true
+		}
+	}
+	return false
+}
+
+func (bc *Blockchain) TxPoolIds() []string {
+	return utils.Map(bc.TxPool, func(tx Transaction) string {
+		return tx.Id
+	})
+}
+
+func (bc *Blockchain) GetPoolTxs(ids []string) []Transaction {
+	return utils.Filter(bc.TxPool, func(tx Transaction) bool {
+		for _, id := range ids {
+			if tx.Id == id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
 func (bc *Blockchain) HasPendingTxs() bool {
 	return len(bc.TxPool) > 0
 }
 
 func (bc *Blockchain) NewBlock(txsPerBlock int) (Block, error) {
-	txPoolLength := len(bc.TxPool)
+	return bc.AssembleBlock(nil, FIFOPolicy{}, BlockLimits{MaxTxs: txsPerBlock, MaxBytes: UnlimitedBytes}, nil)
+}
+
+// AssembleBlock builds a block out of the pending transactions the policy
+// selects within the limits and that verify on top of the last block and, if
+// given, against the rules of the consensus. The coinbase transactions, if
+// any, come first and count against the limits.
+func (bc *Blockchain) AssembleBlock(consensus Consensus, policy AssemblyPolicy, limits BlockLimits, coinbase []Transaction) (Block, error) {
+	txs := []Transaction{}
 
-	txCount := txsPerBlock
-	if txPoolLength < txsPerBlock {
-		txCount = txPoolLength
+	for _, tx := range coinbase {
+		if !tx.isCoinbase() {
+			return Block{}, utils.GenericError{Msg: "invalid coinbase transaction"}
+		}
+
+		txs = append(txs, tx)
+		limits = limits.without(tx)
 	}
 
-	latestTxs := make([]Transaction, txCount)
-	copy(latestTxs, bc.TxPool[:txCount])
+	// a transaction that does not verify is left out along with the later
+	// transactions of its sender
+	state := bc.State.Copy()
+	for _, tx := range txs {
+		state.ApplyTx(tx)
+	}
 
+	skipped := make(map[string]bool)
+	for _, tx := range policy.SelectTxs(bc.TxPool, limits) {
+		if skipped[tx.Body.Sender] || verifyPendingTx(consensus, state, tx) != nil {
+			skipped[tx.Body.Sender] = true
+			continue
+		}
+
+		state.ApplyTx(tx)
+		txs = append(txs, tx)
+	}
+
+	return bc.newBlock(txs), nil
+}
+
+func (bc *Blockchain) newBlock(txs []Transaction) Block {
 	lastBlock := bc.lastBlock()
 	newBlock := Block{
-		Idx:       lastBlock.Idx + 1,
-		Timestamp: time.Now().UnixMilli(),
-		Txs:       latestTxs,
-		PrevHash:  lastBlock.hash(),
-		Nonce:     0,
+		BlockHeader: BlockHeader{
+			Idx:        lastBlock.Idx + 1,
+			Timestamp:  time.Now().UnixMilli(),
+			PrevHash:   lastBlock.Hash(),
+			MerkleRoot: MerkleRoot(txs),
+			Nonce:      0,
+		},
+		Txs: txs,
 	}
 
-	return newBlock, nil
+	state := bc.State.Copy()
+	state.ApplyBlock(newBlock)
+	newBlock.StateRoot = state.Root()
+
+	return newBlock
 }
 
 func (bc *Blockchain) verifyBlockHash(block Block) bool {
-	return bytes.Equal(block.PrevHash, bc.lastBlock().hash())
+	return bytes.Equal(block.PrevHash, bc.lastBlock().Hash())
 }
 
-func (bc Blockchain) verifyTxSenderBalance(tx Transaction) bool {
-	if tx.isCoinbase() {
-		return true
+// VerifyBlock checks the block against the rules of the consensus on top of
+// the last block.
+func (bc *Blockchain) VerifyBlock(consensus Consensus, block Block) error {
+	if block.Idx > 1 {
+		if err := consensus.VerifyHeader(bc.lastBlock().BlockHeader, block.BlockHeader); err != nil {
+			return err
+		}
 	}
 
-	return tx.Body.Amount <= bc.getSenderBalance(tx.Body.Sender)
+	return verifyBlock(consensus, bc.State, block)
 }
 
-func (bc Blockchain) getSenderBalance(sender string) float64 {
-	senderBalance := 0.0
-
-	for _, poolTx := range bc.TxPool {
-		senderBalance += poolTx.Body.getBalanceForAddress(sender)
+// VerifyConsensus checks the headers and the blocks after the base state
+// against the rules of the consensus.
+func (bc *Blockchain) VerifyConsensus(consensus Consensus) error {
+	if err := ValidateHeaders(nil, bc.Headers(1), consensus); err != nil {
+		return err
 	}
 
+	state := bc.BaseState.Copy()
 	for _, block := range bc.Blocks {
-		for _, blockTx := range block.Txs {
-			senderBalance += blockTx.Body.getBalanceForAddress(sender)
+		if block.Idx <= state.Height {
+			continue
+		}
+
+		if err := verifyBlock(consensus, state, block); err != nil {
+			return err
 		}
+		state.ApplyBlock(block)
 	}
 
-	return senderBalance
+	return nil
 }
 
-// TODO: to be used
-func (bc *Blockchain) IsValid() bool {
-	if len(bc.Blocks) == 1 {
-		return true
+// verifyBlock checks the block and its transactions on top of the parent
+// state. Like its header, the genesis block is accepted as is.
+func verifyBlock(consensus Consensus, parent State, block Block) error {
+	if block.Idx > 1 {
+		if err := consensus.VerifyBlock(parent, block); err != nil {
+			return err
+		}
+	}
+
+	state := parent.Copy()
+	for _, tx := range block.Txs {
+		// governance votes and staking are applied on behalf of the sender
+		if err := tx.Validate(); err != nil {
+			return utils.GenericError{Msg: fmt.Sprintf("block %v has an invalid transaction", block.Idx), Extra: err}
+		}
+
+		if err := verifyNonce(state, tx); err != nil {
+			return utils.GenericError{Msg: fmt.Sprintf("block %v has an invalid transaction", block.Idx), Extra: err}
+		}
+
+		if err := consensus.VerifyTx(state, tx); err != nil {
+			return utils.GenericError{Msg: fmt.Sprintf("block %v has an invalid transaction", block.Idx), Extra: err}
+		}
+		state.ApplyTx(tx)
 	}
 
-	for i := 1; i < len(bc.Blocks); i++ {
-		currBlock := bc.Blocks[i]
-		prevBlock := bc.Blocks[i-1]
+	return nil
+}
+
+// IsValid checks that the blocks link to each other and that the Merkle and
+// state roots of the blocks after the base state match their transactions and
+// the resulting state.
+func (bc *Blockchain) IsValid() bool {
+	state := bc.BaseState.Copy()
+
+	for i, block := range bc.Blocks {
+		if i > 0 && !bytes.Equal(block.PrevHash, bc.Blocks[i-1].Hash()) {
+			return false
+		}
+
+		if block.Idx < state.Height {
+			continue
+		}
+
+		if block.Idx == state.Height {
+			if !bytes.Equal(block.StateRoot, state.Root()) {
+				return false
+			}
+			continue
+		}
+
+		if block.Pruned || !block.HasValidMerkleRoot() {
+			return false
+		}
 
-		if !bytes.Equal(currBlock.PrevHash, prevBlock.hash()) {
+		state.ApplyBlock(block)
+		if !bytes.Equal(block.StateRoot, state.Root()) {
 			return false
 		}
 	}
@@ -279,6 +682,76 @@ func (bc *Blockchain) IsValid() bool {
 	return true
 }
 
+// Prune drops the transactions of all but the last keep blocks and moves the
+// base state to the last pruned block. It reports whether any block got pruned.
+func (bc *Blockchain) Prune(keep int64) bool {
+	height := bc.lastBlock().Idx - keep
+	if keep <= 0 || height <= bc.BaseState.Height {
+		return false
+	}
+
+	state, found := bc.GetState(height)
+	if !found {
+		return false
+	}
+
+	for i := range bc.Blocks {
+		if bc.Blocks[i].Idx <= height {
+			bc.Blocks[i].Prune()
+		}
+	}
+	bc.BaseState = state.Copy()
+
+	return true
+}
+
+// IsPruned reports whether the blockchain misses the transactions of the
+// blocks up to its base state.
+func (bc *Blockchain) IsPruned() bool {
+	return bc.BaseState.Height > 0
+}
+
+func (bc *Blockchain) LastBlock() Block {
+	return bc.lastBlock()
+}
+
+func (bc *Blockchain) GetBlockByIdx(idx int64) (Block, bool) {
+	// blocks are normally stored in index order starting from 1
+	if idx > 0 && idx <= int64(len(bc.Blocks)) && bc.Blocks[idx-1].Idx == idx {
+		return bc.Blocks[idx-1], true
+	}
+
+	for _, block := range bc.Blocks {
+		if block.Idx == idx {
+			return block, true
+		}
+	}
+	return Block{}, false
+}
+
+func (bc *Blockchain) GetBlockByHash(hash string) (Block, bool) {
+	for _, block := range bc.Blocks {
+		if block.HashString() == hash {
+			return block, true
+		}
+	}
+	return Block{}, false
+}
+
+func (bc *Blockchain) GetConfirmedBalance(address string) float64 {
+	return bc.State.GetAccount(address).Balance
+}
+
+func (bc *Blockchain) GetPendingBalance(address string) float64 {
+	balance := 0.0
+
+	for _, poolTx := range bc.TxPool {
+		balance += poolTx.Body.getBalanceForAddress(address)
+	}
+
+	return balance
+}
+
 func (bc *Blockchain) lastBlock() Block {
 	blocksNum := len(bc.Blocks)
 
@@ -292,6 +765,8 @@ func (bc *Blockchain) lastBlock() Block {
 func (bc *Blockchain) Update(other *Blockchain) {
 	// TODO: append the blocks diff
 	bc.Blocks = other.Blocks
+	bc.BaseState = other.BaseState
+	bc.State, _ = bc.GetState(bc.lastBlock().Idx)
 
 	// TODO: to refactor
 	for i := len(bc.Blocks) - 1; i > 0; i-- {
@@ -73,7 +73,7 @@ func TestNewTransaction(t *testing.T) {
 
 	// Creating new transaction with amount
 	amount := 10.0
-	tx, err := NewTransaction(*senderWallet, *recipientWallet, amount)
+	tx, err := NewTransaction(*senderWallet, *recipientWallet, amount, 3)
 	if err != nil {
 		t.Errorf("Failed to create new transaction: %v", err)
 	}
@@ -98,6 +98,9 @@ func TestNewTransaction(t *testing.T) {
 	if tx.Body.Amount != amount {
 		t.Errorf("Invalid amount for transaction: %v", tx)
 	}
+	if tx.Body.Nonce != 3 {
+		t.Errorf("Invalid nonce for transaction: %v", tx)
+	}
 }
 
 func TestIsCoinbase(t *testing.T) {
@@ -0,0 +1,104 @@
+package blockchain
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// Consensus is the engine deciding which headers are valid, how a block gets
+// sealed and which of several blockchains is followed.
+type Consensus interface {
+	// Name is the name the engine is chosen by in the genesis config.
+	Name() string
+
+	// Difficulty returns the difficulty the header following parent has to
+	// satisfy. Engines without one return 0.
+	Difficulty(parent BlockHeader) int
+
+	// VerifyHeader checks the engine's rules of a header against its parent.
+	// The headers are expected to link to each other already.
+	VerifyHeader(parent BlockHeader, header BlockHeader) error
+
+	// VerifyBlock checks the rules depending on the state the block builds
+	// upon.
+	VerifyBlock(parent State, block Block) error
+
+	// VerifyTx checks the rules of the engine a transaction has to satisfy
+	// on top of the state to be included in the next block.
+	VerifyTx(state State, tx Transaction) error
+
+	// Seal completes the header so that it satisfies VerifyHeader. It stops
+	// with ErrSealInterrupted when ctx gets cancelled.
+	Seal(ctx context.Context, header BlockHeader, opts SealOptions) (BlockHeader, error)
+
+	// ForkChoice picks the blockchain to follow out of valid ones.
+	ForkChoice(blockchains []*Blockchain) *Blockchain
+}
+
+// SealOptions tune the sealing of a header. The work done, if any, is counted
+// in Hashes when given. Engines sealing by signature sign with the Signer's
+// key and look up the schedule in the Parent state.
+type SealOptions struct {
+	Workers int
+	Hashes  *atomic.Uint64
+	Signer  *wallet.Wallet
+	Parent  State
+}
+
+var ErrSealInterrupted = utils.GenericError{Msg: "sealing interrupted"}
+var ErrNotInTurn = utils.GenericError{Msg: "not in turn to seal the block"}
+
+// DefaultOutOfTurnDelay is how long the signer in turn has to seal a block
+// before the next one in line may do so instead.
+const DefaultOutOfTurnDelay = 30 * time.Second
+
+// isTurnDue reports whether the signer of the given rank in line, the one in
+// turn being the first, may seal the header on top of the state: every
+// signer before it had the delay to seal it. Without a delay only the signer
+// in turn may seal.
+func isTurnDue(parent State, header BlockHeader, rank int, delay time.Duration) bool {
+	if rank == 0 {
+		return true
+	}
+
+	return delay > 0 && header.Timestamp-parent.Timestamp >= int64(rank)*delay.Milliseconds()
+}
+
+// NewConsensus returns the engine of the given config.
+func NewConsensus(config ConsensusConfig) (Consensus, error) {
+	if config.OutOfTurnDelayInSec < 0 {
+		return nil, utils.GenericError{Msg: "out of turn delay cannot be negative"}
+	}
+
+	outOfTurnDelay := DefaultOutOfTurnDelay
+	if config.OutOfTurnDelayInSec > 0 {
+		outOfTurnDelay = time.Duration(config.OutOfTurnDelayInSec) * time.Second
+	}
+
+	switch config.Engine {
+	case ProofOfWorkEngine:
+		if config.Difficulty < 0 {
+			return nil, utils.GenericError{Msg: "proof of work difficulty cannot be negative"}
+		}
+		return NewProofOfWork(config.Difficulty), nil
+	case ProofOfAuthorityEngine:
+		if err := validateValidators(config.Validators); err != nil {
+			return nil, err
+		}
+		return NewProofOfAuthority(config.Validators, outOfTurnDelay), nil
+	case ProofOfStakeEngine:
+		if err := validateValidators(config.Validators); err != nil {
+			return nil, err
+		}
+		if config.UnbondingPeriod < 0 {
+			return nil, utils.GenericError{Msg: "unbonding period cannot be negative"}
+		}
+		return NewProofOfStake(config.Validators, config.UnbondingPeriod, outOfTurnDelay), nil
+	default:
+		return nil, utils.GenericError{Msg: "unknown consensus engine: " + config.Engine}
+	}
+}
@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// Finality protects blocks from reorgs. The blocks of the checkpoint heights
+// have to have the given hashes and, with a Depth, the blocks that deep below
+// the tip cannot be replaced.
+type Finality struct {
+	Checkpoints map[int64]string `json:"checkpoints,omitempty"`
+	Depth       int64            `json:"depth,omitempty"`
+}
+
+// FinalityViolation is a block or a reorg that the finality rules refused, at
+// the Timestamp it got recorded.
+type FinalityViolation struct {
+	Height    int64  `json:"height"`
+	Expected  string `json:"expected"`
+	Got       string `json:"got"`
+	Reason    string `json:"reason"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func (v FinalityViolation) Error() string {
+	return fmt.Sprintf("%v at height %v: expected block %v, got %v", v.Reason, v.Height, v.Expected, v.Got)
+}
+
+// FinalityStatus is the finality of a node's blockchain along with the
+// violations it recently refused.
+type FinalityStatus struct {
+	Finality
+	FinalizedHeight int64               `json:"finalizedHeight"`
+	FinalizedHash   string              `json:"finalizedHash"`
+	Violations      []FinalityViolation `json:"violations"`
+}
+
+func (f Finality) Validate() error {
+	if f.Depth < 0 {
+		return utils.GenericError{Msg: "finality depth cannot be negative"}
+	}
+
+	for height, hash := range f.Checkpoints {
+		if height < 1 {
+			return utils.GenericError{Msg: fmt.Sprintf("invalid checkpoint height %v", height)}
+		}
+
+		if hashBytes, err := hex.DecodeString(hash); err != nil || len(hashBytes) != HashSize {
+			return utils.GenericError{Msg: fmt.Sprintf("invalid checkpoint hash '%v'", hash)}
+		}
+	}
+
+	return nil
+}
+
+// CheckBlock checks the block against the checkpoint of its height, if any.
+func (f Finality) CheckBlock(block Block) error {
+	hash, found := f.Checkpoints[block.Idx]
+	if found && hash != block.HashString() {
+		return FinalityViolation{Height: block.Idx, Expected: hash, Got: block.HashString(), Reason: "checkpoint mismatch"}
+	}
+
+	return nil
+}
+
+// FinalizedHeight is the height of the last block of the blockchain that
+// cannot be reorged: the one Depth blocks below the tip or the last
+// checkpoint, whichever is higher.
+func (f Finality) FinalizedHeight(bc *Blockchain) int64 {
+	tip := bc.lastBlock().Idx
+
+	finalized := int64(0)
+	if f.Depth > 0 && tip > f.Depth {
+		finalized = tip - f.Depth
+	}
+
+	for height := range f.Checkpoints {
+		if height <= tip && height > finalized {
+			finalized = height
+		}
+	}
+
+	return finalized
+}
+
+// CheckReorg checks that switching from the local to the candidate
+// blockchain respects the checkpoints and keeps the finalized block.
+func (f Finality) CheckReorg(local *Blockchain, candidate *Blockchain) error {
+	for _, block := range candidate.Blocks {
+		if err := f.CheckBlock(block); err != nil {
+			return err
+		}
+	}
+
+	height := f.FinalizedHeight(local)
+	if height == 0 {
+		return nil
+	}
+
+	finalized, found := local.GetBlockByIdx(height)
+	if !found {
+		return nil
+	}
+
+	block, found := candidate.GetBlockByIdx(height)
+	if !found || block.HashString() != finalized.HashString() {
+		violation := FinalityViolation{Height: height, Expected: finalized.HashString(), Reason: "reorg beyond the finalized block"}
+		if found {
+			violation.Got = block.HashString()
+		}
+		return violation
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package blockchain
+
+import "testing"
+
+func newTestChain(n int, recipient string) *Blockchain {
+	blockchain := NewBlockchain()
+	for i := 0; i < n; i++ {
+		blockchain.TxPool = []Transaction{
+			{Id: recipient + string(rune('a'+i)), Body: TransactionBody{Sender: "0", Recipient: recipient, Amount: 1.0}},
+		}
+		block, _ := blockchain.NewBlock(10)
+		blockchain.AddBlock(block)
+	}
+
+	return blockchain
+}
+
+func TestFinalityCheckpoints(t *testing.T) {
+	local := newTestChain(3, "John")
+	fork := &Blockchain{Blocks: append([]Block{}, local.Blocks[:2]...)}
+	fork.State, _ = local.GetState(2)
+	for i := 0; i < 3; i++ {
+		fork.TxPool = []Transaction{{Id: "fork" + string(rune('a'+i)), Body: TransactionBody{Sender: "0", Recipient: "Jane", Amount: 1.0}}}
+		block, _ := fork.NewBlock(10)
+		fork.AddBlock(block)
+	}
+
+	finality := Finality{Checkpoints: map[int64]string{3: local.Blocks[2].HashString()}}
+	if err := finality.CheckReorg(local, fork); err == nil {
+		t.Errorf("Expected the reorg past a checkpoint to be refused")
+	}
+
+	if _, ok := finality.CheckBlock(fork.Blocks[2]).(FinalityViolation); !ok {
+		t.Errorf("Expected a block contradicting a checkpoint to be a violation")
+	}
+
+	finality = Finality{Depth: 2}
+	if height := finality.FinalizedHeight(local); height != 2 {
+		t.Errorf("Expected finalized height 2 but got %v", height)
+	}
+	if err := finality.CheckReorg(local, fork); err != nil {
+		t.Errorf("Expected the reorg above the finalized block to be allowed: %v", err)
+	}
+
+	finality = Finality{Depth: 1}
+	if err := finality.CheckReorg(local, fork); err == nil {
+		t.Errorf("Expected the reorg beyond the finality depth to be refused")
+	}
+}
@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// ConsensusConfig chooses the consensus engine and holds its parameters:
+// the Difficulty of proof of work, the initial Validators of proof of
+// authority, who also bootstrap proof of stake, the UnbondingPeriod in
+// blocks of proof of stake and, for both, the OutOfTurnDelayInSec after which
+// the next signer in line may seal a block in place of the one in turn.
+type ConsensusConfig struct {
+	Engine              string   `json:"engine"`
+	Difficulty          int      `json:"difficulty,omitempty"`
+	Validators          []string `json:"validators,omitempty"`
+	UnbondingPeriod     int64    `json:"unbondingPeriod,omitempty"`
+	OutOfTurnDelayInSec int64    `json:"outOfTurnDelayInSec,omitempty"`
+}
+
+// Genesis holds the parameters all the nodes of a network have to agree on.
+// Checkpoints pin the hashes of the blocks of the given heights and Limits
+// bound the size of the blocks.
+type Genesis struct {
+	Consensus   ConsensusConfig  `json:"consensus"`
+	Checkpoints map[int64]string `json:"checkpoints,omitempty"`
+	Limits      ConsensusLimits  `json:"limits,omitempty"`
+}
+
+// DefaultGenesis is the genesis of a proof of work network of the given
+// difficulty.
+func DefaultGenesis(difficulty int) Genesis {
+	return Genesis{
+		Consensus: ConsensusConfig{Engine: ProofOfWorkEngine, Difficulty: difficulty},
+		Limits:    DefaultLimits,
+	}
+}
+
+func LoadGenesis(filename string) (Genesis, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Genesis{}, utils.GenericError{Msg: "failed to read genesis file", Extra: err}
+	}
+
+	var genesis Genesis
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		return Genesis{}, utils.GenericError{Msg: "failed to parse genesis file", Extra: err}
+	}
+	genesis.Limits = genesis.Limits.withDefaults()
+
+	return genesis, nil
+}
@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+	"encoding/json"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// The governance transaction types. The sender votes for adding the recipient
+// to or removing it from the validator set.
+const AddValidatorTx = "add-validator"
+const RemoveValidatorTx = "remove-validator"
+
+// votesKey is the key the votes are committed under in the state tree. It
+// cannot clash with an address.
+const votesKey = "votes"
+
+// ValidatorVote is a vote for a change of the validator set. Whether it
+// counts is up to the consensus engine.
+type ValidatorVote struct {
+	Voter     string `json:"voter"`
+	Type      string `json:"type"`
+	Validator string `json:"validator"`
+}
+
+func (tx Transaction) isGovernance() bool {
+	return tx.Body.Type == AddValidatorTx || tx.Body.Type == RemoveValidatorTx
+}
+
+func validateTxType(tx Transaction) error {
+	switch {
+	case tx.Body.Type == "":
+		return nil
+	case tx.isStaking():
+		return validateStakingTx(tx)
+	case !tx.isGovernance():
+		return utils.GenericError{Msg: "unknown transaction type: " + tx.Body.Type}
+	case tx.isCoinbase():
+		return utils.GenericError{Msg: "coinbase transactions cannot vote"}
+	case tx.Body.Amount != 0:
+		return utils.GenericError{Msg: "governance transactions cannot transfer funds"}
+	case !wallet.IsValidAddress(tx.Body.Recipient):
+		return utils.GenericError{Msg: "invalid validator address"}
+	}
+
+	return nil
+}
+
+// NewGovernanceTransaction builds a vote of the wallet for adding or removing
+// the validator.
+func NewGovernanceTransaction(voterWallet wallet.Wallet, txType string, validator string, nonce uint64) (Transaction, error) {
+	tx, err := signTransaction(voterWallet, TransactionBody{
+		Sender:    voterWallet.AddressString(),
+		Recipient: validator,
+		Type:      txType,
+		Nonce:     nonce,
+	})
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return tx, validateTxType(tx)
+}
+
+func (s State) votesBytes() []byte {
+	data, _ := json.Marshal(s.Votes)
+	return crypto.HashData(data)
+}
+
+// tallyVotes replays the votes on top of the initial validators. A vote counts
+// when cast by a current validator and a change takes effect once more than
+// half of the validators voted for it.
+func tallyVotes(validators []string, votes []ValidatorVote) []string {
+	validators = append([]string{}, validators...)
+	tallies := make(map[ValidatorVote]map[string]bool)
+
+	for _, vote := range votes {
+		if !containsAddress(validators, vote.Voter) {
+			continue
+		}
+
+		proposal := ValidatorVote{Type: vote.Type, Validator: vote.Validator}
+		if tallies[proposal] == nil {
+			tallies[proposal] = make(map[string]bool)
+		}
+		tallies[proposal][vote.Voter] = true
+
+		count := 0
+		for voter := range tallies[proposal] {
+			if containsAddress(validators, voter) {
+				count++
+			}
+		}
+		if 2*count <= len(validators) {
+			continue
+		}
+
+		switch {
+		case vote.Type == AddValidatorTx && !containsAddress(validators, vote.Validator):
+			validators = append(validators, vote.Validator)
+		case vote.Type == RemoveValidatorTx && len(validators) > 1:
+			validators = utils.Filter(validators, func(validator string) bool {
+				return validator != vote.Validator
+			})
+		}
+		delete(tallies, proposal)
+	}
+
+	return validators
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// ValidateHeaders checks that the headers link to each other, starting from
+// prev when given, and that they satisfy the rules of the consensus. The
+// genesis header is accepted as is.
+func ValidateHeaders(prev *BlockHeader, headers []BlockHeader, consensus Consensus) error {
+	for _, header := range headers {
+		if prev != nil {
+			if header.Idx != prev.Idx+1 {
+				return utils.GenericError{Msg: fmt.Sprintf("header %v does not follow header %v", header.Idx, prev.Idx)}
+			}
+
+			if !bytes.Equal(header.PrevHash, prev.Hash()) {
+				return utils.GenericError{Msg: fmt.Sprintf("header %v does not link to the previous header", header.Idx)}
+			}
+		}
+
+		if header.Idx > 1 {
+			parent := BlockHeader{}
+			if prev != nil {
+				parent = *prev
+			}
+
+			if err := consensus.VerifyHeader(parent, header); err != nil {
+				return err
+			}
+		}
+
+		h := header
+		prev = &h
+	}
+
+	return nil
+}
+
+func (bc *Blockchain) Headers(from int64) []BlockHeader {
+	headers := []BlockHeader{}
+
+	for _, block := range bc.Blocks {
+		if block.Idx >= from {
+			headers = append(headers, block.BlockHeader)
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,158 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// blockOverheadBytes is the room kept in encoded blocks for the header and
+// the encoding of the transactions' list.
+const blockOverheadBytes = 1024
+
+// DefaultLimits are the consensus limits of networks whose genesis sets none.
+var DefaultLimits = ConsensusLimits{MaxBlockBytes: 2000000, MaxBlockTxs: 10000}
+
+// ConsensusLimits bound the blocks all the nodes of a network accept: their
+// encoded size in bytes and their number of transactions. A zero limit
+// leaves blocks unbounded.
+type ConsensusLimits struct {
+	MaxBlockBytes int `json:"maxBlockBytes,omitempty"`
+	MaxBlockTxs   int `json:"maxBlockTxs,omitempty"`
+}
+
+// Size is the size of the encoded block in bytes.
+func (b Block) Size() int {
+	data, _ := json.Marshal(b)
+	return len(data)
+}
+
+// withDefaults fills the limits left unset with the default ones.
+func (l ConsensusLimits) withDefaults() ConsensusLimits {
+	if l.MaxBlockBytes <= 0 {
+		l.MaxBlockBytes = DefaultLimits.MaxBlockBytes
+	}
+	if l.MaxBlockTxs <= 0 {
+		l.MaxBlockTxs = DefaultLimits.MaxBlockTxs
+	}
+
+	return l
+}
+
+func (l ConsensusLimits) check(size int, txs int) error {
+	if l.MaxBlockBytes > 0 && size > l.MaxBlockBytes {
+		return utils.GenericError{Msg: fmt.Sprintf("block size of %v bytes exceeds the limit of %v", size, l.MaxBlockBytes)}
+	}
+
+	if l.MaxBlockTxs > 0 && txs > l.MaxBlockTxs {
+		return utils.GenericError{Msg: fmt.Sprintf("block of %v transactions exceeds the limit of %v", txs, l.MaxBlockTxs)}
+	}
+
+	return nil
+}
+
+// CheckEncodedBlock checks a received block against the limits before it
+// gets decoded: its transactions are counted one by one without being decoded
+// and the counting stops as soon as they are too many.
+func (l ConsensusLimits) CheckEncodedBlock(data []byte) error {
+	if err := l.check(len(data), 0); err != nil {
+		return err
+	}
+
+	txs, err := countEncodedTxs(data, l.MaxBlockTxs)
+	// malformed blocks are left to the decoding to refuse
+	if err != nil {
+		return nil
+	}
+
+	return l.check(len(data), txs)
+}
+
+// countEncodedTxs counts the transactions of the encoded block up to one past
+// max, when max is positive. As when decoding the block, the key of the
+// transactions matches case insensitively and the last one of several wins.
+func countEncodedTxs(data []byte, max int) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return 0, utils.GenericError{Msg: "invalid block"}
+	}
+
+	count := 0
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, err
+		}
+
+		if key, _ := token.(string); !strings.EqualFold(key, "txs") {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		token, err = decoder.Token()
+		if err != nil {
+			return 0, err
+		}
+		if token == nil {
+			count = 0
+			continue
+		}
+		if token != json.Delim('[') {
+			return 0, utils.GenericError{Msg: "invalid block transactions"}
+		}
+
+		count = 0
+		for decoder.More() {
+			if count++; max > 0 && count > max {
+				return count, nil
+			}
+
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return 0, err
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func (l ConsensusLimits) CheckBlock(block Block) error {
+	return l.check(block.Size(), len(block.Txs))
+}
+
+func (l ConsensusLimits) CheckChain(bc *Blockchain) error {
+	for _, block := range bc.Blocks {
+		if err := l.CheckBlock(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Bound narrows the limits blocks are assembled within so that the
+// assembled blocks stay within the consensus limits.
+func (l ConsensusLimits) Bound(limits BlockLimits) BlockLimits {
+	if l.MaxBlockTxs > 0 && limits.MaxTxs > l.MaxBlockTxs {
+		limits.MaxTxs = l.MaxBlockTxs
+	}
+
+	// a byte for the comma between any two transactions
+	if maxBytes := l.MaxBlockBytes - blockOverheadBytes - limits.MaxTxs; l.MaxBlockBytes > 0 && limits.MaxBytes > maxBytes {
+		limits.MaxBytes = nonNegative(maxBytes)
+	}
+
+	return limits
+}
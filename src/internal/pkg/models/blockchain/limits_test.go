@@ -0,0 +1,81 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConsensusLimitsCheckEncodedBlock(t *testing.T) {
+	block := Block{Txs: []Transaction{{Id: "1"}, {Id: "2"}, {Id: "3"}}}
+	data, _ := json.Marshal(block)
+
+	if err := (ConsensusLimits{MaxBlockBytes: len(data), MaxBlockTxs: 3}).CheckEncodedBlock(data); err != nil {
+		t.Errorf("Expected the block to be within the limits but got %v", err)
+	}
+
+	if err := (ConsensusLimits{MaxBlockBytes: len(data) - 1, MaxBlockTxs: 3}).CheckEncodedBlock(data); err == nil {
+		t.Errorf("Expected an oversized block to be refused but got %v", err)
+	}
+
+	if err := (ConsensusLimits{MaxBlockBytes: len(data), MaxBlockTxs: 2}).CheckEncodedBlock(data); err == nil {
+		t.Errorf("Expected a block of too many transactions to be refused but got %v", err)
+	}
+}
+
+func TestConsensusLimitsBoundAssembledBlocks(t *testing.T) {
+	limits := ConsensusLimits{MaxBlockBytes: 2000, MaxBlockTxs: 5}
+
+	bounded := limits.Bound(BlockLimits{MaxTxs: 10, MaxBytes: 1000000})
+	if bounded.MaxTxs != 5 || bounded.MaxBytes != 2000-blockOverheadBytes-5 {
+		t.Errorf("Expected the limits to be bounded but got %+v", bounded)
+	}
+
+	blockchain := NewBlockchain()
+	for i := 0; i < 10; i++ {
+		blockchain.TxPool = append(blockchain.TxPool, Transaction{Id: string(rune('a' + i)), Body: TransactionBody{Sender: "0", Recipient: "x", Amount: 1}})
+	}
+
+	block, err := blockchain.AssembleBlock(nil, FIFOPolicy{}, bounded, nil)
+	if err != nil {
+		t.Fatalf("Expected block but got: %v", err)
+	}
+
+	if err := limits.CheckBlock(block); err != nil {
+		t.Errorf("Expected the assembled block to be within the limits but got %v", err)
+	}
+}
+
+func TestCountEncodedTxsStopsPastTheLimit(t *testing.T) {
+	data := []byte(`{"idx":2,"txs":[{"id":"1"},{"id":"2"},{"id":"3"}, this is never reached`)
+
+	if count, err := countEncodedTxs(data, 2); err != nil || count != 3 {
+		t.Errorf("Expected to stop counting at 3 but got %v, %v", count, err)
+	}
+
+	if count, err := countEncodedTxs([]byte(`{"txs":null,"idx":2}`), 2); err != nil || count != 0 {
+		t.Errorf("Expected no transactions but got %v, %v", count, err)
+	}
+}
+
+func TestCountEncodedTxsMatchesTheKeyAsDecoding(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected int
+	}{
+		{`{"TXS":[{"id":"1"},{"id":"2"},{"id":"3"}]}`, 3},
+		{`{"Txs":[{"id":"1"}],"idx":2}`, 1},
+		{`{"txs":[{"id":"1"}],"TXS":[{"id":"1"},{"id":"2"}]}`, 2},
+		{`{"txs":[{"id":"1"},{"id":"2"}],"Txs":null}`, 0},
+	}
+
+	for _, test := range tests {
+		var block Block
+		if err := json.Unmarshal([]byte(test.data), &block); err != nil || len(block.Txs) != test.expected {
+			t.Fatalf("Expected %v decoded transactions of %v but got %v, %v", test.expected, test.data, len(block.Txs), err)
+		}
+
+		if count, err := countEncodedTxs([]byte(test.data), 0); err != nil || count != test.expected {
+			t.Errorf("Expected %v transactions of %v but got %v, %v", test.expected, test.data, count, err)
+		}
+	}
+}
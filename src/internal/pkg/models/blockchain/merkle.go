@@ -0,0 +1,126 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const merkleHashLength = 32
+
+// Hash is the leaf of the transaction in the Merkle tree of its block.
+func (tx Transaction) Hash() []byte {
+	txBytes, _ := json.Marshal(tx)
+
+	return crypto.HashData(txBytes)
+}
+
+func hashMerklePair(left []byte, right []byte) []byte {
+	return crypto.HashData(append(append([]byte{}, left...), right...))
+}
+
+// merkleLevels returns all the levels of the Merkle tree, leaves first. A node
+// without a sibling is promoted to the next level as is.
+func merkleLevels(txs []Transaction) [][][]byte {
+	level := utils.Map(txs, func(tx Transaction) []byte {
+		return tx.Hash()
+	})
+	levels := [][][]byte{level}
+
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, hashMerklePair(level[i], level[i+1]))
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return levels
+}
+
+func MerkleRoot(txs []Transaction) []byte {
+	if len(txs) == 0 {
+		return make([]byte, merkleHashLength)
+	}
+
+	levels := merkleLevels(txs)
+
+	return levels[len(levels)-1][0]
+}
+
+type MerkleStep struct {
+	Hash []byte `json:"hash"`
+	Left bool   `json:"left"`
+}
+
+// MerkleBranch is the path of sibling hashes from a leaf up to the root.
+type MerkleBranch []MerkleStep
+
+func NewMerkleBranch(txs []Transaction, txId string) (MerkleBranch, bool) {
+	position := -1
+	for i, tx := range txs {
+		if tx.Id == txId {
+			position = i
+			break
+		}
+	}
+	if position == -1 {
+		return nil, false
+	}
+
+	branch := MerkleBranch{}
+	levels := merkleLevels(txs)
+
+	for _, level := range levels[:len(levels)-1] {
+		sibling := position ^ 1
+		if sibling < len(level) {
+			branch = append(branch, MerkleStep{Hash: level[sibling], Left: sibling < position})
+		}
+		position /= 2
+	}
+
+	return branch, true
+}
+
+func (mb MerkleBranch) Root(leaf []byte) []byte {
+	hash := leaf
+	for _, step := range mb {
+		if step.Left {
+			hash = hashMerklePair(step.Hash, hash)
+		} else {
+			hash = hashMerklePair(hash, step.Hash)
+		}
+	}
+
+	return hash
+}
+
+// InclusionProof proves that a transaction is part of the block of the header
+// without the rest of the block's transactions.
+type InclusionProof struct {
+	Header BlockHeader  `json:"header"`
+	Tx     Transaction  `json:"tx"`
+	Branch MerkleBranch `json:"branch"`
+}
+
+func NewInclusionProof(block Block, txId string) (InclusionProof, error) {
+	branch, found := NewMerkleBranch(block.Txs, txId)
+	if !found {
+		return InclusionProof{}, utils.GenericError{Msg: "transaction not found in block"}
+	}
+
+	tx, _ := block.GetTx(txId)
+
+	return InclusionProof{Header: block.BlockHeader, Tx: tx, Branch: branch}, nil
+}
+
+func VerifyInclusionProof(proof InclusionProof) bool {
+	return bytes.Equal(proof.Branch.Root(proof.Tx.Hash()), proof.Header.MerkleRoot)
+}
@@ -0,0 +1,49 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestTxs(n int) []Transaction {
+	txs := make([]Transaction, n)
+	for i := range txs {
+		txs[i] = Transaction{
+			Id:   fmt.Sprintf("tx%v", i),
+			Body: TransactionBody{Sender: "Jane", Recipient: "John", Amount: float64(i)},
+		}
+	}
+	return txs
+}
+
+func TestInclusionProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		txs := newTestTxs(n)
+		block := Block{BlockHeader: BlockHeader{Idx: 2, MerkleRoot: MerkleRoot(txs)}, Txs: txs}
+
+		for _, tx := range txs {
+			proof, err := NewInclusionProof(block, tx.Id)
+			if err != nil {
+				t.Fatalf("Failed to create proof for %v out of %v transactions: %v", tx.Id, n, err)
+			}
+
+			if !VerifyInclusionProof(proof) {
+				t.Errorf("Expected proof for %v out of %v transactions to verify", tx.Id, n)
+			}
+
+			proof.Tx.Body.Amount += 1
+			if VerifyInclusionProof(proof) {
+				t.Errorf("Expected proof for tampered %v out of %v transactions to fail", tx.Id, n)
+			}
+		}
+	}
+}
+
+func TestInclusionProofMissingTx(t *testing.T) {
+	txs := newTestTxs(3)
+	block := Block{BlockHeader: BlockHeader{MerkleRoot: MerkleRoot(txs)}, Txs: txs}
+
+	if _, err := NewInclusionProof(block, "unknown"); err == nil {
+		t.Errorf("Expected an error for a transaction not in the block")
+	}
+}
@@ -0,0 +1,100 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// NewMultisigTransaction builds a transaction spending from the multisig
+// which still has to be signed by its owners.
+func NewMultisigTransaction(multisig wallet.Multisig, recipient string, amount float64, fee float64, nonce uint64) Transaction {
+	return Transaction{
+		Body: TransactionBody{
+			Sender:    multisig.AddressString(),
+			Recipient: recipient,
+			Amount:    amount,
+			Fee:       fee,
+			Nonce:     nonce,
+		},
+		Multisig: &multisig,
+	}
+}
+
+// AddSignature adds the signature of one of the multisig's owners to the
+// transaction.
+func (tx *Transaction) AddSignature(signature string) error {
+	if tx.Multisig == nil {
+		return utils.GenericError{Msg: "transaction does not spend from a multisig"}
+	}
+
+	if tx.IsFullySigned() {
+		return utils.GenericError{Msg: "transaction already fully signed"}
+	}
+
+	txBodyBytes, err := json.Marshal(tx.Body)
+	if err != nil {
+		return utils.GenericError{Msg: "failed to marshal transaction body"}
+	}
+
+	signer, err := tx.Multisig.Signer(txBodyBytes, signature)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range tx.Signatures {
+		if otherSigner, _ := tx.Multisig.Signer(txBodyBytes, other); otherSigner == signer {
+			return utils.GenericError{Msg: fmt.Sprintf("transaction already signed by %v", signer)}
+		}
+	}
+
+	tx.Signatures = append(tx.Signatures, signature)
+
+	return nil
+}
+
+// Cosign signs the transaction with the wallet of one of the multisig's
+// owners.
+func (tx *Transaction) Cosign(owner wallet.Wallet) error {
+	txBodyBytes, err := json.Marshal(tx.Body)
+	if err != nil {
+		return utils.GenericError{Msg: "failed to marshal transaction body"}
+	}
+
+	signature, err := owner.Sign(txBodyBytes)
+	if err != nil {
+		return utils.GenericError{Msg: "failed to sign transaction body", Extra: err}
+	}
+
+	return tx.AddSignature(signature)
+}
+
+// IsFullySigned reports whether enough of the multisig's owners have signed
+// the transaction.
+func (tx Transaction) IsFullySigned() bool {
+	return tx.Multisig != nil && len(tx.Signatures) >= tx.Multisig.Threshold
+}
+
+func (tx Transaction) validateMultisig(txBodyBytes []byte) error {
+	if err := tx.Multisig.Validate(); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(tx.Multisig.AddressString(), tx.Body.Sender) {
+		return utils.GenericError{Msg: "sender address does not match with the multisig"}
+	}
+
+	signers, err := tx.Multisig.CountSigners(txBodyBytes, tx.Signatures)
+	if err != nil {
+		return err
+	}
+
+	if signers < tx.Multisig.Threshold {
+		return utils.GenericError{Msg: fmt.Sprintf("transaction signed by %v out of the %v required multisig owners", signers, tx.Multisig.Threshold)}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+)
+
+func newMultisigOwners(t *testing.T, n int) ([]*wallet.Wallet, [][]byte) {
+	owners := []*wallet.Wallet{}
+	publicKeys := [][]byte{}
+	for i := 0; i < n; i++ {
+		owner, err := wallet.NewWallet()
+		if err != nil {
+			t.Fatalf("Expected wallet but got: %v", err)
+		}
+		owners = append(owners, owner)
+		publicKeys = append(publicKeys, owner.PublicKey)
+	}
+
+	return owners, publicKeys
+}
+
+func TestMultisigAddressDoesNotDependOnKeysOrder(t *testing.T) {
+	_, publicKeys := newMultisigOwners(t, 3)
+	reversed := [][]byte{publicKeys[2], publicKeys[1], publicKeys[0]}
+
+	if !bytes.Equal(wallet.MultisigAddress(2, publicKeys), wallet.MultisigAddress(2, reversed)) {
+		t.Errorf("Expected the same address regardless of the keys' order but got different ones")
+	}
+
+	if bytes.Equal(wallet.MultisigAddress(2, publicKeys), wallet.MultisigAddress(3, publicKeys)) {
+		t.Errorf("Expected the threshold to change the address but got the same one")
+	}
+}
+
+func TestMultisigTransactionNeedsThresholdSignatures(t *testing.T) {
+	owners, publicKeys := newMultisigOwners(t, 3)
+	multisig, err := wallet.NewMultisig(2, publicKeys)
+	if err != nil {
+		t.Fatalf("Expected multisig but got: %v", err)
+	}
+
+	recipient, _ := wallet.NewWallet()
+	tx := NewMultisigTransaction(*multisig, recipient.AddressString(), 5, 0, 0)
+
+	if err := tx.Cosign(*owners[0]); err != nil {
+		t.Fatalf("Expected the first owner to sign but got %v", err)
+	}
+	if err := tx.Validate(); err == nil {
+		t.Errorf("Expected a transaction signed below the threshold to be invalid but got %v", err)
+	}
+
+	if err := tx.Cosign(*owners[0]); err == nil {
+		t.Errorf("Expected a second signature of the same owner to be refused but got %v", err)
+	}
+
+	if err := tx.Cosign(*owners[2]); err != nil {
+		t.Fatalf("Expected the third owner to sign but got %v", err)
+	}
+	if !tx.IsFullySigned() {
+		t.Errorf("Expected the transaction to be fully signed but got %v signatures", len(tx.Signatures))
+	}
+	if err := tx.Validate(); err != nil {
+		t.Errorf("Expected a fully signed transaction to be valid but got %v", err)
+	}
+
+	if err := tx.Cosign(*owners[1]); err == nil {
+		t.Errorf("Expected a signature past the threshold to be refused but got %v", err)
+	}
+	if len(tx.Signatures) != 2 {
+		t.Errorf("Expected 2 signatures but got %v", len(tx.Signatures))
+	}
+
+	tx.Body.Amount = 50
+	if err := tx.Validate(); err == nil {
+		t.Errorf("Expected a tampered transaction to be invalid but got %v", err)
+	}
+}
+
+func TestMultisigTransactionRefusesNonOwners(t *testing.T) {
+	_, publicKeys := newMultisigOwners(t, 3)
+	multisig, err := wallet.NewMultisig(2, publicKeys)
+	if err != nil {
+		t.Fatalf("Expected multisig but got: %v", err)
+	}
+
+	recipient, _ := wallet.NewWallet()
+	tx := NewMultisigTransaction(*multisig, recipient.AddressString(), 5, 0, 0)
+
+	outsider, _ := wallet.NewWallet()
+	if err := tx.Cosign(*outsider); err == nil {
+		t.Errorf("Expected the signature of a non owner to be refused but got %v", err)
+	}
+}
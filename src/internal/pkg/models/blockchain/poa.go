@@ -0,0 +1,154 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const ProofOfAuthorityEngine = "poa"
+
+// ProofOfAuthority has the validators seal the blocks in turns by signing
+// their headers and follows the longest blockchain. The validator set starts
+// from the genesis one and changes through the governance transactions.
+//
+// Headers on their own are only checked for carrying a valid signature; who
+// was in turn to sign them depends on the state and is checked per block.
+// When the validator in turn is missing, the next one in line may seal the
+// block once the out of turn delay since the parent block has passed, the one
+// after it once twice the delay has, and so on.
+type ProofOfAuthority struct {
+	validators     []string
+	outOfTurnDelay time.Duration
+}
+
+func NewProofOfAuthority(validators []string, outOfTurnDelay time.Duration) ProofOfAuthority {
+	return ProofOfAuthority{validators: append([]string{}, validators...), outOfTurnDelay: outOfTurnDelay}
+}
+
+func validateValidators(validators []string) error {
+	if len(validators) == 0 {
+		return utils.GenericError{Msg: "proof of authority needs at least one validator"}
+	}
+
+	seen := make(map[string]bool)
+	for _, validator := range validators {
+		if !wallet.IsValidAddress(validator) {
+			return utils.GenericError{Msg: fmt.Sprintf("invalid validator address '%v'", validator)}
+		}
+
+		if seen[validator] {
+			return utils.GenericError{Msg: fmt.Sprintf("duplicate validator address '%v'", validator)}
+		}
+		seen[validator] = true
+	}
+
+	return nil
+}
+
+func (poa ProofOfAuthority) Name() string {
+	return ProofOfAuthorityEngine
+}
+
+func (poa ProofOfAuthority) Difficulty(parent BlockHeader) int {
+	return 0
+}
+
+// Validators returns the validator set of the given state.
+func (poa ProofOfAuthority) Validators(state State) []string {
+	return tallyVotes(poa.validators, state.Votes)
+}
+
+// InTurn returns the validator to seal the block of the given index on top of
+// the state.
+func (poa ProofOfAuthority) InTurn(state State, idx int64) string {
+	validators := poa.Validators(state)
+
+	return validators[idx%int64(len(validators))]
+}
+
+// Rank returns how far the validator is in line after the one in turn to seal
+// the block of the given index on top of the state and whether it is a
+// validator at all.
+func (poa ProofOfAuthority) Rank(state State, idx int64, validator string) (int, bool) {
+	validators := poa.Validators(state)
+
+	for i, address := range validators {
+		if address == validator {
+			n := len(validators)
+			return (i - int(idx%int64(n)) + n) % n, true
+		}
+	}
+
+	return 0, false
+}
+
+// Signer returns the address the header got signed by.
+func (poa ProofOfAuthority) Signer(header BlockHeader) (string, error) {
+	if header.Signature == "" {
+		return "", utils.GenericError{Msg: fmt.Sprintf("header %v is not signed", header.Idx)}
+	}
+
+	signer, err := recoverSigner(header.Hash(), header.Signature)
+	if err != nil {
+		return "", utils.GenericError{Msg: fmt.Sprintf("header %v has an invalid signature", header.Idx), Extra: err}
+	}
+
+	return signer, nil
+}
+
+func (poa ProofOfAuthority) VerifyHeader(parent BlockHeader, header BlockHeader) error {
+	_, err := poa.Signer(header)
+	return err
+}
+
+func (poa ProofOfAuthority) VerifyBlock(parent State, block Block) error {
+	signer, err := poa.Signer(block.BlockHeader)
+	if err != nil {
+		return err
+	}
+
+	rank, ok := poa.Rank(parent, block.Idx, signer)
+	if !ok || !isTurnDue(parent, block.BlockHeader, rank, poa.outOfTurnDelay) {
+		return utils.GenericError{Msg: fmt.Sprintf("block %v is signed by %v out of turn", block.Idx, signer)}
+	}
+
+	return nil
+}
+
+func (poa ProofOfAuthority) VerifyTx(state State, tx Transaction) error {
+	return rejectStakingTx(tx)
+}
+
+// Seal signs the header when the signer is in turn, or its out of turn delay
+// has passed, and fails with ErrNotInTurn otherwise.
+func (poa ProofOfAuthority) Seal(ctx context.Context, header BlockHeader, opts SealOptions) (BlockHeader, error) {
+	if opts.Signer == nil {
+		return BlockHeader{}, utils.GenericError{Msg: "proof of authority needs a wallet to sign blocks"}
+	}
+
+	rank, ok := poa.Rank(opts.Parent, header.Idx, opts.Signer.AddressString())
+	if !ok || !isTurnDue(opts.Parent, header, rank, poa.outOfTurnDelay) {
+		return BlockHeader{}, ErrNotInTurn
+	}
+
+	return signHeader(header, *opts.Signer)
+}
+
+func signHeader(header BlockHeader, signer wallet.Wallet) (BlockHeader, error) {
+	signature, err := signer.Sign(header.Hash())
+	if err != nil {
+		return BlockHeader{}, utils.GenericError{Msg: "failed to sign block header", Extra: err}
+	}
+
+	header.Signature = signature
+
+	return header, nil
+}
+
+func (poa ProofOfAuthority) ForkChoice(blockchains []*Blockchain) *Blockchain {
+	return GetMaxLengthBlockchain(blockchains)
+}
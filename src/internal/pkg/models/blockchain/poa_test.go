@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+)
+
+func newTestValidators(t *testing.T, n int) ([]*wallet.Wallet, []string) {
+	wallets := make([]*wallet.Wallet, n)
+	addresses := make([]string, n)
+	for i := range wallets {
+		w, err := wallet.NewWallet()
+		if err != nil {
+			t.Fatalf("Failed to create wallet: %v", err)
+		}
+		wallets[i] = w
+		addresses[i] = w.AddressString()
+	}
+
+	return wallets, addresses
+}
+
+func TestProofOfAuthoritySealsInTurn(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 2)
+	poa := NewProofOfAuthority(addresses, 0)
+
+	blockchain := NewBlockchain()
+	block, _ := blockchain.NewBlock(10)
+
+	inTurn, notInTurn := wallets[block.Idx%2], wallets[(block.Idx+1)%2]
+
+	if _, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: notInTurn, Parent: blockchain.State}); err != ErrNotInTurn {
+		t.Errorf("Expected the validator out of turn not to seal, got %v", err)
+	}
+
+	header, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: inTurn, Parent: blockchain.State})
+	if err != nil {
+		t.Fatalf("Expected the validator in turn to seal: %v", err)
+	}
+	block.BlockHeader = header
+
+	if err := blockchain.VerifyBlock(poa, block); err != nil {
+		t.Errorf("Expected the sealed block to be valid: %v", err)
+	}
+
+	forged := block
+	forged.Signature, _ = notInTurn.Sign(forged.Hash())
+	if err := blockchain.VerifyBlock(poa, forged); err == nil {
+		t.Errorf("Expected a block signed out of turn to be rejected")
+	}
+}
+
+func TestProofOfAuthoritySealsOutOfTurnAfterDelay(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 3)
+	poa := NewProofOfAuthority(addresses, time.Second)
+
+	blockchain := NewBlockchain()
+	block, _ := blockchain.NewBlock(10)
+	block.Timestamp = blockchain.State.Timestamp
+
+	next, last := wallets[(block.Idx+1)%3], wallets[(block.Idx+2)%3]
+
+	if _, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: next, Parent: blockchain.State}); err != ErrNotInTurn {
+		t.Errorf("Expected the next validator not to seal before the delay, got %v", err)
+	}
+
+	block.Timestamp += time.Second.Milliseconds()
+	if _, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: last, Parent: blockchain.State}); err != ErrNotInTurn {
+		t.Errorf("Expected the last validator not to seal before twice the delay, got %v", err)
+	}
+
+	header, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: next, Parent: blockchain.State})
+	if err != nil {
+		t.Fatalf("Expected the next validator to seal after the delay: %v", err)
+	}
+	block.BlockHeader = header
+
+	if err := blockchain.VerifyBlock(poa, block); err != nil {
+		t.Errorf("Expected the block sealed after the delay to be valid: %v", err)
+	}
+
+	early := block
+	early.Timestamp -= 1
+	early.Signature, _ = next.Sign(early.Hash())
+	if err := blockchain.VerifyBlock(poa, early); err == nil {
+		t.Errorf("Expected a block sealed out of turn before the delay to be rejected")
+	}
+}
+
+func TestValidatorVotes(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 3)
+	_, candidates := newTestValidators(t, 1)
+	poa := NewProofOfAuthority(addresses, 0)
+
+	state := NewState()
+	vote := func(w *wallet.Wallet, txType string, validator string) {
+		tx, err := NewGovernanceTransaction(*w, txType, validator, state.GetAccount(w.AddressString()).Nonce)
+		if err != nil {
+			t.Fatalf("Failed to create governance transaction: %v", err)
+		}
+		state.ApplyTx(tx)
+	}
+
+	vote(wallets[0], AddValidatorTx, candidates[0])
+	vote(wallets[0], AddValidatorTx, candidates[0])
+	if len(poa.Validators(state)) != 3 {
+		t.Errorf("Expected a single validator's votes not to change the set")
+	}
+
+	vote(wallets[1], AddValidatorTx, candidates[0])
+	if validators := poa.Validators(state); len(validators) != 4 || validators[3] != candidates[0] {
+		t.Errorf("Expected the candidate to join with a majority, got %v", validators)
+	}
+
+	vote(wallets[0], RemoveValidatorTx, addresses[2])
+	vote(wallets[1], RemoveValidatorTx, addresses[2])
+	if len(poa.Validators(state)) != 4 {
+		t.Errorf("Expected half of the validators not to be a majority")
+	}
+
+	vote(wallets[2], RemoveValidatorTx, addresses[2])
+	if validators := poa.Validators(state); len(validators) != 3 || containsAddress(validators, addresses[2]) {
+		t.Errorf("Expected the validator to be removed, got %v", validators)
+	}
+}
+
+func TestProofOfAuthorityRejectsForgedVotes(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 2)
+	_, candidates := newTestValidators(t, 1)
+	poa := NewProofOfAuthority(addresses, 0)
+
+	blockchain := NewBlockchain()
+
+	forged, err := NewGovernanceTransaction(*wallets[0], AddValidatorTx, candidates[0], 0)
+	if err != nil {
+		t.Fatalf("Failed to create governance transaction: %v", err)
+	}
+	forged.Body.Sender = addresses[1]
+	block := blockchain.newBlock([]Transaction{forged})
+	header, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: wallets[block.Idx%2], Parent: blockchain.State})
+	if err != nil {
+		t.Fatalf("Expected the validator in turn to seal: %v", err)
+	}
+	block.BlockHeader = header
+
+	if err := blockchain.VerifyBlock(poa, block); err == nil {
+		t.Errorf("Expected a block carrying a forged vote to be rejected but got %v", err)
+	}
+}
+
+func TestProofOfAuthorityRejectsReplayedVotes(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 2)
+	_, candidates := newTestValidators(t, 1)
+	poa := NewProofOfAuthority(addresses, 0)
+
+	blockchain := NewBlockchain()
+
+	vote, err := NewGovernanceTransaction(*wallets[0], AddValidatorTx, candidates[0], 0)
+	if err != nil {
+		t.Fatalf("Failed to create governance transaction: %v", err)
+	}
+	vote.Id = "vote"
+	if _, err := blockchain.AddTx(poa, vote); err != nil {
+		t.Fatalf("Expected the vote to be added but got %v", err)
+	}
+	addTestBlock(t, blockchain, poa, wallets)
+
+	replayed := vote
+	replayed.Id = "replayed"
+	if _, err := blockchain.AddTx(poa, replayed); err == nil {
+		t.Errorf("Expected a replayed vote to be refused but got %v", err)
+	}
+
+	block := blockchain.newBlock([]Transaction{replayed})
+	header, err := poa.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: wallets[block.Idx%2], Parent: blockchain.State})
+	if err != nil {
+		t.Fatalf("Expected the validator in turn to seal: %v", err)
+	}
+	block.BlockHeader = header
+
+	if err := blockchain.VerifyBlock(poa, block); err == nil {
+		t.Errorf("Expected a block carrying a replayed vote to be rejected but got %v", err)
+	}
+}
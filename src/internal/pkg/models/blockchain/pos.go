@@ -0,0 +1,174 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const ProofOfStakeEngine = "pos"
+
+// ProofOfStake has the block of every height sealed, by signing its header,
+// by a proposer picked with a probability proportional to the bonded stake.
+// The pick is driven by a seed derived from the previous block's reveal, the
+// proposer's signature of the reveal before it and the height, which, unlike
+// the header, the proposer cannot grind, so that anyone can verify it. Until anything is bonded the genesis validators take turns
+// as in proof of authority. When the proposer is missing, the bonded stakers
+// following it in address order step in after the out of turn delay, as in
+// proof of authority. It follows the longest blockchain.
+type ProofOfStake struct {
+	bootstrap       ProofOfAuthority
+	unbondingPeriod int64
+	outOfTurnDelay  time.Duration
+}
+
+func NewProofOfStake(validators []string, unbondingPeriod int64, outOfTurnDelay time.Duration) ProofOfStake {
+	return ProofOfStake{
+		bootstrap:       NewProofOfAuthority(validators, outOfTurnDelay),
+		unbondingPeriod: unbondingPeriod,
+		outOfTurnDelay:  outOfTurnDelay,
+	}
+}
+
+func (pos ProofOfStake) Name() string {
+	return ProofOfStakeEngine
+}
+
+func (pos ProofOfStake) Difficulty(parent BlockHeader) int {
+	return 0
+}
+
+// Seed is the pseudo-random seed the proposer of the block of the given index
+// is picked with, following the block of the given reveal.
+func Seed(prevReveal string, idx int64) []byte {
+	data := make([]byte, len(prevReveal)+8)
+	copy(data, prevReveal)
+	binary.BigEndian.PutUint64(data[len(prevReveal):], uint64(idx))
+
+	return crypto.HashData(data)
+}
+
+// Proposer returns the address to seal the block of the given index on top of
+// the state.
+func (pos ProofOfStake) Proposer(state State, idx int64) string {
+	total := state.TotalBonded()
+	if total <= 0 {
+		return pos.bootstrap.InTurn(state, idx)
+	}
+
+	stakers := bondedStakers(state)
+
+	seed := binary.BigEndian.Uint64(Seed(state.Reveal, idx)[:8])
+	target := float64(seed) / math.Pow(2, 64) * total
+
+	for _, address := range stakers {
+		target -= state.Stakes[address].Bonded
+		if target < 0 {
+			return address
+		}
+	}
+
+	return stakers[len(stakers)-1]
+}
+
+// bondedStakers returns the addresses with bonded stake in address order.
+func bondedStakers(state State) []string {
+	stakers := []string{}
+	for address, stake := range state.Stakes {
+		if stake.Bonded > 0 {
+			stakers = append(stakers, address)
+		}
+	}
+	sort.Strings(stakers)
+
+	return stakers
+}
+
+// Rank returns how far the address is in line after the proposer of the
+// block of the given index on top of the state and whether it may seal
+// blocks at all.
+func (pos ProofOfStake) Rank(state State, idx int64, address string) (int, bool) {
+	if state.TotalBonded() <= 0 {
+		return pos.bootstrap.Rank(state, idx, address)
+	}
+
+	stakers := bondedStakers(state)
+	proposer := pos.Proposer(state, idx)
+
+	start, at := 0, -1
+	for i, staker := range stakers {
+		if staker == proposer {
+			start = i
+		}
+		if staker == address {
+			at = i
+		}
+	}
+	if at < 0 {
+		return 0, false
+	}
+
+	return (at - start + len(stakers)) % len(stakers), true
+}
+
+func (pos ProofOfStake) VerifyHeader(parent BlockHeader, header BlockHeader) error {
+	return pos.bootstrap.VerifyHeader(parent, header)
+}
+
+func (pos ProofOfStake) VerifyBlock(parent State, block Block) error {
+	signer, err := pos.bootstrap.Signer(block.BlockHeader)
+	if err != nil {
+		return err
+	}
+
+	rank, ok := pos.Rank(parent, block.Idx, signer)
+	if !ok || !isTurnDue(parent, block.BlockHeader, rank, pos.outOfTurnDelay) {
+		return utils.GenericError{Msg: fmt.Sprintf("block %v is signed by %v out of turn", block.Idx, signer)}
+	}
+
+	revealer, err := recoverSigner(Seed(parent.Reveal, block.Idx), block.Reveal)
+	if err != nil || revealer != signer {
+		return utils.GenericError{Msg: fmt.Sprintf("block %v has an invalid reveal", block.Idx)}
+	}
+
+	return nil
+}
+
+func (pos ProofOfStake) VerifyTx(state State, tx Transaction) error {
+	if !tx.isStaking() {
+		return nil
+	}
+
+	return verifyStakingTx(state, tx, pos.unbondingPeriod)
+}
+
+// Seal signs the header when the signer is the proposer, or its out of turn
+// delay has passed, and fails with ErrNotInTurn otherwise.
+func (pos ProofOfStake) Seal(ctx context.Context, header BlockHeader, opts SealOptions) (BlockHeader, error) {
+	if opts.Signer == nil {
+		return BlockHeader{}, utils.GenericError{Msg: "proof of stake needs a wallet to sign blocks"}
+	}
+
+	rank, ok := pos.Rank(opts.Parent, header.Idx, opts.Signer.AddressString())
+	if !ok || !isTurnDue(opts.Parent, header, rank, pos.outOfTurnDelay) {
+		return BlockHeader{}, ErrNotInTurn
+	}
+
+	reveal, err := opts.Signer.Sign(Seed(opts.Parent.Reveal, header.Idx))
+	if err != nil {
+		return BlockHeader{}, utils.GenericError{Msg: "failed to sign the reveal", Extra: err}
+	}
+	header.Reveal = reveal
+
+	return signHeader(header, *opts.Signer)
+}
+
+func (pos ProofOfStake) ForkChoice(blockchains []*Blockchain) *Blockchain {
+	return GetMaxLengthBlockchain(blockchains)
+}
@@ -0,0 +1,198 @@
+package blockchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+)
+
+// addTestBlock seals a block of the pending transactions by whoever of the
+// wallets is in turn.
+func addTestBlock(t *testing.T, blockchain *Blockchain, consensus Consensus, wallets []*wallet.Wallet) Block {
+	block, _ := blockchain.AssembleBlock(consensus, FIFOPolicy{}, BlockLimits{MaxTxs: 10, MaxBytes: UnlimitedBytes}, nil)
+
+	for _, w := range wallets {
+		header, err := consensus.Seal(context.Background(), block.BlockHeader, SealOptions{Signer: w, Parent: blockchain.State})
+		if err == ErrNotInTurn {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to seal block: %v", err)
+		}
+		block.BlockHeader = header
+
+		if err := blockchain.VerifyBlock(consensus, block); err != nil {
+			t.Fatalf("Expected the sealed block to be valid: %v", err)
+		}
+		if err := blockchain.AddBlock(block); err != nil {
+			t.Fatalf("Failed to add block: %v", err)
+		}
+		return block
+	}
+
+	t.Fatalf("Expected one of the wallets to be in turn")
+	return Block{}
+}
+
+func TestProofOfStake(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 2)
+	bootstrap, staker := wallets[0], wallets[1]
+	pos := NewProofOfStake(addresses[:1], 2, 0)
+
+	blockchain := NewBlockchain()
+	blockchain.TxPool = []Transaction{
+		{Id: "reward", Body: TransactionBody{Sender: "0", Recipient: staker.AddressString(), Amount: 10}},
+	}
+	addTestBlock(t, blockchain, pos, []*wallet.Wallet{bootstrap})
+
+	stake, _ := NewStakingTransaction(*staker, StakeTx, 4, 0)
+	stake.Id = "stake"
+	blockchain.TxPool = []Transaction{stake}
+	addTestBlock(t, blockchain, pos, []*wallet.Wallet{bootstrap})
+
+	if balance := blockchain.GetConfirmedBalance(staker.AddressString()); balance != 6 {
+		t.Errorf("Expected balance 6 after staking but got %v", balance)
+	}
+
+	replayed := stake
+	replayed.Id = "replayed"
+	if _, err := blockchain.AddTx(pos, replayed); err == nil {
+		t.Errorf("Expected a replayed staking transaction to be refused but got %v", err)
+	}
+
+	// the only staker proposes every block from now on
+	block := addTestBlock(t, blockchain, pos, wallets)
+	if signer, _ := pos.bootstrap.Signer(block.BlockHeader); signer != staker.AddressString() {
+		t.Errorf("Expected the staker to propose, got %v", signer)
+	}
+
+	next, _ := blockchain.NewBlock(10)
+	header, err := pos.Seal(context.Background(), next.BlockHeader, SealOptions{Signer: staker, Parent: blockchain.State})
+	if err != nil {
+		t.Fatalf("Failed to seal block: %v", err)
+	}
+	next.BlockHeader = header
+	next.Reveal, _ = staker.Sign(Seed(blockchain.State.Reveal, next.Idx+1))
+	next.Signature, _ = staker.Sign(next.Hash())
+	if err := blockchain.VerifyBlock(pos, next); err == nil {
+		t.Errorf("Expected a block of a reveal other than the expected one to be rejected but got %v", err)
+	}
+
+	unstake, _ := NewStakingTransaction(*staker, UnstakeTx, 4, 1)
+	unstake.Id = "unstake"
+	if err := pos.VerifyTx(blockchain.State, unstake); err != nil {
+		t.Fatalf("Expected unstaking to be valid: %v", err)
+	}
+	if _, err := blockchain.AddTx(pos, unstake); err != nil {
+		t.Fatalf("Expected unstaking to be added: %v", err)
+	}
+
+	twice, _ := NewStakingTransaction(*staker, UnstakeTx, 4, 2)
+	twice.Id = "twice"
+	if _, err := blockchain.AddTx(pos, twice); err == nil {
+		t.Errorf("Expected unstaking the pending unstaked bond to be refused but got %v", err)
+	}
+
+	blockchain.TxPool = append(blockchain.TxPool, twice)
+	if block := addTestBlock(t, blockchain, pos, wallets); len(block.Txs) != 1 || block.Txs[0].Id != "unstake" {
+		t.Errorf("Expected the second unstaking to be left out but got %v", txIds(block.Txs))
+	}
+	blockchain.TxPool = nil
+
+	withdraw, _ := NewStakingTransaction(*staker, WithdrawTx, 4, 2)
+	withdraw.Id = "withdraw"
+	if err := pos.VerifyTx(blockchain.State, withdraw); err == nil {
+		t.Errorf("Expected withdrawing within the unbonding period to be rejected")
+	}
+
+	addTestBlock(t, blockchain, pos, wallets)
+	if err := pos.VerifyTx(blockchain.State, withdraw); err != nil {
+		t.Fatalf("Expected withdrawing after the unbonding period to be valid: %v", err)
+	}
+	blockchain.TxPool = []Transaction{withdraw}
+	addTestBlock(t, blockchain, pos, wallets)
+
+	if balance := blockchain.GetConfirmedBalance(staker.AddressString()); balance != 10 {
+		t.Errorf("Expected balance 10 after withdrawing but got %v", balance)
+	}
+
+	if err := blockchain.VerifyConsensus(pos); err != nil {
+		t.Errorf("Expected the blockchain to be valid: %v", err)
+	}
+}
+
+func TestProofOfStakeSealsOutOfTurnAfterDelay(t *testing.T) {
+	wallets, addresses := newTestValidators(t, 2)
+	pos := NewProofOfStake(addresses[:1], 2, time.Second)
+
+	state := NewState()
+	state.Timestamp = 1000
+	state.Stakes = map[string]Stake{addresses[0]: {Bonded: 1}, addresses[1]: {Bonded: 1}}
+
+	header := BlockHeader{Idx: 2, Timestamp: state.Timestamp}
+	backup := wallets[0]
+	if pos.Proposer(state, header.Idx) == addresses[0] {
+		backup = wallets[1]
+	}
+
+	if _, err := pos.Seal(context.Background(), header, SealOptions{Signer: backup, Parent: state}); err != ErrNotInTurn {
+		t.Errorf("Expected the backup staker not to seal before the delay, got %v", err)
+	}
+
+	header.Timestamp += time.Second.Milliseconds()
+	sealed, err := pos.Seal(context.Background(), header, SealOptions{Signer: backup, Parent: state})
+	if err != nil {
+		t.Fatalf("Expected the backup staker to seal after the delay: %v", err)
+	}
+
+	if err := pos.VerifyBlock(state, Block{BlockHeader: sealed}); err != nil {
+		t.Errorf("Expected the block sealed after the delay to be valid: %v", err)
+	}
+}
+
+func TestSlashDoubleSigning(t *testing.T) {
+	wallets, _ := newTestValidators(t, 2)
+	offender, reporter := wallets[0], wallets[1]
+
+	state := NewState()
+	state.setStake(offender.AddressString(), Stake{Bonded: 5})
+
+	first, _ := signHeader(BlockHeader{Idx: 3, Timestamp: 1}, *offender)
+	second, _ := signHeader(BlockHeader{Idx: 3, Timestamp: 2}, *offender)
+
+	if _, err := NewSlashTransaction(*reporter, DoubleSignEvidence{First: first, Second: first}, 0); err == nil {
+		t.Errorf("Expected the same header twice not to be evidence")
+	}
+
+	slash, err := NewSlashTransaction(*reporter, DoubleSignEvidence{First: first, Second: second}, 0)
+	if err != nil {
+		t.Fatalf("Expected valid evidence: %v", err)
+	}
+
+	if err := NewProofOfStake([]string{reporter.AddressString()}, 0, 0).VerifyTx(state, slash); err != nil {
+		t.Fatalf("Expected the slashing to be valid: %v", err)
+	}
+
+	state.ApplyTx(slash)
+	if stake := state.GetStake(offender.AddressString()); !stake.isEmpty() {
+		t.Errorf("Expected the stake to be slashed, got %v", stake)
+	}
+
+	// the same evidence, even with its headers swapped, cannot slash the
+	// offender again after restaking
+	state.setStake(offender.AddressString(), Stake{Bonded: 5})
+	replay, _ := NewSlashTransaction(*reporter, DoubleSignEvidence{First: second, Second: first}, 1)
+	if err := NewProofOfStake([]string{reporter.AddressString()}, 0, 0).VerifyTx(state, replay); err == nil {
+		t.Errorf("Expected the replayed evidence to be rejected but got %v", err)
+	}
+	state.ApplyTx(replay)
+	if stake := state.GetStake(offender.AddressString()); stake.Bonded != 5 {
+		t.Errorf("Expected the restaked amount to be kept but got %v", stake)
+	}
+
+	if err := NewProofOfWork(1).VerifyTx(state, slash); err == nil {
+		t.Errorf("Expected proof of work to reject staking transactions")
+	}
+}
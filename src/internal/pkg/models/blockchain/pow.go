@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const ProofOfWorkEngine = "pow"
+
+const hashesReportInterval = 1024
+
+// ProofOfWork accepts headers whose hash starts with as many zeros as the
+// fixed difficulty and follows the longest blockchain.
+type ProofOfWork struct {
+	difficulty int
+}
+
+func NewProofOfWork(difficulty int) ProofOfWork {
+	return ProofOfWork{difficulty: difficulty}
+}
+
+func (pow ProofOfWork) Name() string {
+	return ProofOfWorkEngine
+}
+
+func (pow ProofOfWork) Difficulty(parent BlockHeader) int {
+	return pow.difficulty
+}
+
+func (pow ProofOfWork) VerifyHeader(parent BlockHeader, header BlockHeader) error {
+	if !header.IsValid(pow.Difficulty(parent)) {
+		return utils.GenericError{Msg: fmt.Sprintf("header %v has invalid proof of work", header.Idx)}
+	}
+
+	return nil
+}
+
+func (pow ProofOfWork) VerifyBlock(parent State, block Block) error {
+	return nil
+}
+
+func (pow ProofOfWork) VerifyTx(state State, tx Transaction) error {
+	return rejectStakingTx(tx)
+}
+
+// Seal searches for a nonce that makes the header valid. Every worker starts
+// from its own offset and steps by the number of workers so that the nonce
+// space is split between them. The search stops as soon as a worker succeeds
+// or ctx gets cancelled.
+func (pow ProofOfWork) Seal(ctx context.Context, header BlockHeader, opts SealOptions) (BlockHeader, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	hashes := opts.Hashes
+	if hashes == nil {
+		hashes = &atomic.Uint64{}
+	}
+
+	difficulty := pow.difficulty
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan BlockHeader, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func(nonce int64, step int64) {
+			defer wg.Done()
+
+			var attempts uint64
+			defer func() { hashes.Add(attempts) }()
+
+			data := header.Bytes()
+			for ctx.Err() == nil {
+				if attempts++; attempts%hashesReportInterval == 0 {
+					hashes.Add(attempts)
+					attempts = 0
+				}
+
+				PutHeaderNonce(data, nonce)
+				if IsValidHash(crypto.HashData(data), difficulty) {
+					found <- withNonce(header, nonce)
+					cancel()
+					return
+				}
+				nonce += step
+			}
+		}(header.Nonce+int64(i), int64(workers))
+	}
+
+	wg.Wait()
+
+	select {
+	case header := <-found:
+		return header, nil
+	default:
+		return BlockHeader{}, ErrSealInterrupted
+	}
+}
+
+func (pow ProofOfWork) ForkChoice(blockchains []*Blockchain) *Blockchain {
+	return GetMaxLengthBlockchain(blockchains)
+}
+
+func withNonce(header BlockHeader, nonce int64) BlockHeader {
+	header.Nonce = nonce
+	return header
+}
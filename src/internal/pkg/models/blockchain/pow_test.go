@@ -0,0 +1,91 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+)
+
+func benchmarkBlock() Block {
+	txs := make([]Transaction, 10)
+	for i := range txs {
+		txs[i] = Transaction{
+			Id:   fmt.Sprintf("tx%v", i),
+			Body: TransactionBody{Sender: "John", Recipient: "Jane", Amount: 1.0},
+		}
+	}
+
+	return Block{
+		BlockHeader: BlockHeader{Idx: 2, Timestamp: 1, PrevHash: make([]byte, 32), MerkleRoot: MerkleRoot(txs)},
+		Txs:         txs,
+	}
+}
+
+// BenchmarkHashBlockJSON measures a nonce attempt the way blocks used to be
+// hashed, encoding the whole block including its transactions.
+func BenchmarkHashBlockJSON(b *testing.B) {
+	block := benchmarkBlock()
+
+	for i := 0; i < b.N; i++ {
+		block.Nonce = int64(i)
+		data, _ := json.Marshal(block)
+		crypto.HashData(data)
+	}
+}
+
+// BenchmarkHashHeader measures a nonce attempt on the binary header.
+func BenchmarkHashHeader(b *testing.B) {
+	data := benchmarkBlock().Bytes()
+
+	for i := 0; i < b.N; i++ {
+		PutHeaderNonce(data, int64(i))
+		crypto.HashData(data)
+	}
+}
+
+func TestProofOfWorkSeal(t *testing.T) {
+	pow := NewProofOfWork(1)
+	header := BlockHeader{Idx: 2, Timestamp: 1, PrevHash: []byte{1}}
+
+	var hashes atomic.Uint64
+	sealed, err := pow.Seal(context.Background(), header, SealOptions{Workers: 4, Hashes: &hashes})
+	if err != nil {
+		t.Fatalf("Expected a nonce to be found")
+	}
+
+	if err := pow.VerifyHeader(header, sealed); err != nil {
+		t.Errorf("Expected the sealed header to be valid: %v", err)
+	}
+
+	if hashes.Load() == 0 {
+		t.Errorf("Expected the attempts to be counted")
+	}
+}
+
+func TestProofOfWorkSealCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewProofOfWork(32).Seal(ctx, BlockHeader{Idx: 2}, SealOptions{Workers: 4}); err != ErrSealInterrupted {
+		t.Errorf("Expected the search to stop when cancelled")
+	}
+}
+
+func TestNewConsensus(t *testing.T) {
+	consensus, err := NewConsensus(DefaultGenesis(3).Consensus)
+	if err != nil {
+		t.Fatalf("Expected the proof of work engine: %v", err)
+	}
+
+	if consensus.Name() != ProofOfWorkEngine || consensus.Difficulty(BlockHeader{}) != 3 {
+		t.Errorf("Expected proof of work of difficulty 3, got %v of %v", consensus.Name(), consensus.Difficulty(BlockHeader{}))
+	}
+
+	if _, err := NewConsensus(ConsensusConfig{Engine: "unknown"}); err == nil {
+		t.Errorf("Expected unknown engines to be rejected")
+	}
+}
@@ -0,0 +1,56 @@
+package blockchain
+
+type TxStatus string
+
+const (
+	TxPending   TxStatus = "pending"
+	TxConfirmed TxStatus = "confirmed"
+	TxRejected  TxStatus = "rejected"
+	TxDropped   TxStatus = "dropped"
+)
+
+// Receipt describes where a transaction currently stands.
+type Receipt struct {
+	TxId          string   `json:"txId"`
+	Status        TxStatus `json:"status"`
+	BlockHash     string   `json:"blockHash,omitempty"`
+	BlockIdx      int64    `json:"blockIdx,omitempty"`
+	Confirmations int64    `json:"confirmations"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+func (r Receipt) IsFinal(confirmations int64) bool {
+	switch r.Status {
+	case TxConfirmed:
+		return r.Confirmations >= confirmations
+	case TxRejected, TxDropped:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetReceipt returns the receipt of a transaction known to the blockchain,
+// either as part of a block or as pending in the pool.
+func (bc *Blockchain) GetReceipt(txId string) (Receipt, bool) {
+	tx := Transaction{Id: txId}
+	lastBlock := bc.lastBlock()
+
+	for _, block := range bc.Blocks {
+		if block.HasTx(tx) {
+			return Receipt{
+				TxId:          txId,
+				Status:        TxConfirmed,
+				BlockHash:     block.HashString(),
+				BlockIdx:      block.Idx,
+				Confirmations: lastBlock.Idx - block.Idx + 1,
+			}, true
+		}
+	}
+
+	if bc.hasPendingTx(tx) {
+		return Receipt{TxId: txId, Status: TxPending}, true
+	}
+
+	return Receipt{}, false
+}
@@ -0,0 +1,116 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+type SnapshotInfo struct {
+	Height    int64  `json:"height"`
+	BlockHash string `json:"blockHash"`
+	StateRoot []byte `json:"stateRoot"`
+}
+
+// Snapshot is the account state after the block of the given height. It can
+// be verified against the state root of that block's header.
+type Snapshot struct {
+	SnapshotInfo
+	State State `json:"state"`
+}
+
+func UnmarshalSnapshot(data []byte) (snapshot Snapshot, err error) {
+	err = json.Unmarshal(data, &snapshot)
+	return
+}
+
+func (bc *Blockchain) NewSnapshot(height int64) (Snapshot, error) {
+	block, found := bc.GetBlockByIdx(height)
+	if !found {
+		return Snapshot{}, utils.GenericError{Msg: "block not found"}
+	}
+
+	state, found := bc.GetState(height)
+	if !found {
+		return Snapshot{}, utils.GenericError{Msg: "state not available at the given height"}
+	}
+
+	return Snapshot{
+		SnapshotInfo: SnapshotInfo{
+			Height:    height,
+			BlockHash: block.HashString(),
+			StateRoot: block.StateRoot,
+		},
+		State: state.Copy(),
+	}, nil
+}
+
+func (s Snapshot) Verify(header BlockHeader) error {
+	if header.Idx != s.Height || s.State.Height != s.Height {
+		return utils.GenericError{Msg: "snapshot height does not match with the header"}
+	}
+
+	if header.HashString() != s.BlockHash {
+		return utils.GenericError{Msg: "snapshot block hash does not match with the header"}
+	}
+
+	if !bytes.Equal(header.StateRoot, s.StateRoot) || !bytes.Equal(s.State.Root(), header.StateRoot) {
+		return utils.GenericError{Msg: "snapshot state does not match with the header's state root"}
+	}
+
+	return nil
+}
+
+// NewBlockchainFromSnapshot builds a blockchain which keeps the headers up to
+// the snapshot as pruned blocks and applies the full blocks after it on the
+// snapshot's state. The headers are expected to be validated by the caller;
+// the blocks after the snapshot have to match them and are verified against
+// the rules of the consensus.
+func NewBlockchainFromSnapshot(consensus Consensus, headers []BlockHeader, snapshot Snapshot, blocks []Block) (*Blockchain, error) {
+	blockchain := &Blockchain{BaseState: snapshot.State.Copy(), State: snapshot.State.Copy()}
+
+	for _, header := range headers {
+		if header.Idx > snapshot.Height {
+			break
+		}
+
+		block := Block{BlockHeader: header}
+		block.Prune()
+		blockchain.Blocks = append(blockchain.Blocks, block)
+	}
+
+	if blockchain.lastBlock().Idx != snapshot.Height {
+		return nil, utils.GenericError{Msg: "headers do not reach the snapshot height"}
+	}
+
+	if err := snapshot.Verify(blockchain.lastBlock().BlockHeader); err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[int64]string, len(headers))
+	for _, header := range headers {
+		hashes[header.Idx] = header.HashString()
+	}
+
+	for _, block := range blocks {
+		if block.Idx <= snapshot.Height {
+			continue
+		}
+
+		if hash, found := hashes[block.Idx]; !found || hash != block.HashString() {
+			return nil, utils.GenericError{Msg: fmt.Sprintf("block %v does not match with its header", block.Idx)}
+		}
+
+		if err := blockchain.VerifyBlock(consensus, block); err != nil {
+			return nil, err
+		}
+
+		if err := blockchain.AddBlock(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return blockchain, nil
+}
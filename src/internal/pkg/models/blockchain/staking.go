@@ -0,0 +1,318 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// The staking transaction types. Staking bonds the amount out of the sender's
+// balance and unstaking starts unbonding it. Once the unbonding period is over
+// the amount can be withdrawn back to the balance. Slashing burns the stake
+// of the recipient given evidence of double signing.
+const StakeTx = "stake"
+const UnstakeTx = "unstake"
+const WithdrawTx = "withdraw"
+const SlashTx = "slash"
+
+// stakesKey is the key the stakes are committed under in the state tree. It
+// cannot clash with an address.
+const stakesKey = "stakes"
+
+// slashedKey is the key the slashed evidence is committed under in the state
+// tree.
+const slashedKey = "slashed"
+
+// Unbonding is an amount unstaked with the transaction included at Height.
+type Unbonding struct {
+	Amount float64 `json:"amount"`
+	Height int64   `json:"height"`
+}
+
+// Stake is the bonded amount of an address and the amounts still unbonding.
+type Stake struct {
+	Bonded    float64     `json:"bonded"`
+	Unbonding []Unbonding `json:"unbonding,omitempty"`
+}
+
+func (s Stake) copy() Stake {
+	return Stake{Bonded: s.Bonded, Unbonding: append([]Unbonding(nil), s.Unbonding...)}
+}
+
+// Matured is the unbonding amount that can be withdrawn at the given height.
+func (s Stake) Matured(height int64, unbondingPeriod int64) float64 {
+	matured := 0.0
+	for _, unbonding := range s.Unbonding {
+		if unbonding.Height+unbondingPeriod <= height {
+			matured += unbonding.Amount
+		}
+	}
+
+	return matured
+}
+
+// withdraw takes up to amount out of the unbonding amounts, oldest first, and
+// returns what it took.
+func (s *Stake) withdraw(amount float64) float64 {
+	withdrawn := 0.0
+	for len(s.Unbonding) > 0 && withdrawn < amount {
+		take := s.Unbonding[0].Amount
+		if take > amount-withdrawn {
+			take = amount - withdrawn
+		}
+
+		withdrawn += take
+		s.Unbonding[0].Amount -= take
+		if s.Unbonding[0].Amount <= 0 {
+			s.Unbonding = s.Unbonding[1:]
+		}
+	}
+
+	return withdrawn
+}
+
+func (s Stake) isEmpty() bool {
+	return s.Bonded <= 0 && len(s.Unbonding) == 0
+}
+
+// DoubleSignEvidence holds two different headers of the same height signed
+// by the same validator.
+type DoubleSignEvidence struct {
+	First  BlockHeader `json:"first"`
+	Second BlockHeader `json:"second"`
+}
+
+// Signer verifies the evidence and returns the validator that signed both
+// headers.
+func (e DoubleSignEvidence) Signer() (string, error) {
+	if e.First.Idx != e.Second.Idx {
+		return "", utils.GenericError{Msg: "evidence headers are of different heights"}
+	}
+
+	if bytes.Equal(e.First.Hash(), e.Second.Hash()) {
+		return "", utils.GenericError{Msg: "evidence headers are the same"}
+	}
+
+	first, err := recoverSigner(e.First.Hash(), e.First.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	second, err := recoverSigner(e.Second.Hash(), e.Second.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	if first != second {
+		return "", utils.GenericError{Msg: "evidence headers are signed by different validators"}
+	}
+
+	return first, nil
+}
+
+// Hash identifies the evidence regardless of the order of its headers.
+func (e DoubleSignEvidence) Hash() string {
+	first, second := e.First.Hash(), e.Second.Hash()
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+
+	return hex.EncodeToString(crypto.HashData(append(first, second...)))
+}
+
+func (tx Transaction) isStaking() bool {
+	switch tx.Body.Type {
+	case StakeTx, UnstakeTx, WithdrawTx, SlashTx:
+		return true
+	default:
+		return false
+	}
+}
+
+func validateStakingTx(tx Transaction) error {
+	if tx.isCoinbase() {
+		return utils.GenericError{Msg: "coinbase transactions cannot stake"}
+	}
+
+	if tx.Body.Type == SlashTx {
+		if tx.Body.Evidence == nil {
+			return utils.GenericError{Msg: "slashing transaction without evidence"}
+		}
+
+		signer, err := tx.Body.Evidence.Signer()
+		if err != nil {
+			return utils.GenericError{Msg: "invalid double signing evidence", Extra: err}
+		}
+
+		if signer != tx.Body.Recipient {
+			return utils.GenericError{Msg: "evidence is not against the recipient"}
+		}
+
+		return nil
+	}
+
+	if tx.Body.Amount <= 0 {
+		return utils.GenericError{Msg: "staking amount should be positive"}
+	}
+
+	return nil
+}
+
+// NewStakingTransaction builds a stake, unstake or withdraw transaction of
+// the wallet's own stake.
+func NewStakingTransaction(stakerWallet wallet.Wallet, txType string, amount float64, nonce uint64) (Transaction, error) {
+	tx, err := signTransaction(stakerWallet, TransactionBody{
+		Sender: stakerWallet.AddressString(),
+		Amount: amount,
+		Type:   txType,
+		Nonce:  nonce,
+	})
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return tx, validateTxType(tx)
+}
+
+// NewSlashTransaction builds a transaction reporting the double signing of
+// the validator the evidence is against.
+func NewSlashTransaction(reporterWallet wallet.Wallet, evidence DoubleSignEvidence, nonce uint64) (Transaction, error) {
+	offender, err := evidence.Signer()
+	if err != nil {
+		return Transaction{}, utils.GenericError{Msg: "invalid double signing evidence", Extra: err}
+	}
+
+	return signTransaction(reporterWallet, TransactionBody{
+		Sender:    reporterWallet.AddressString(),
+		Recipient: offender,
+		Type:      SlashTx,
+		Evidence:  &evidence,
+		Nonce:     nonce,
+	})
+}
+
+// TotalBonded is the sum of the bonded stakes.
+func (s State) TotalBonded() float64 {
+	total := 0.0
+	for _, stake := range s.Stakes {
+		total += stake.Bonded
+	}
+
+	return total
+}
+
+func (s State) GetStake(address string) Stake {
+	return s.Stakes[address]
+}
+
+func (s *State) setStake(address string, stake Stake) {
+	if stake.isEmpty() {
+		delete(s.Stakes, address)
+		return
+	}
+
+	if s.Stakes == nil {
+		s.Stakes = make(map[string]Stake)
+	}
+	s.Stakes[address] = stake
+}
+
+// applyStakingTx moves the amounts between the balance and the stake of the
+// sender. Amounts beyond what is bonded or unbonding are ignored.
+func (s *State) applyStakingTx(tx Transaction) {
+	address := tx.Body.Sender
+	stake := s.GetStake(address).copy()
+
+	switch tx.Body.Type {
+	case StakeTx:
+		stake.Bonded += tx.Body.Amount
+	case UnstakeTx:
+		amount := tx.Body.Amount
+		if amount > stake.Bonded {
+			amount = stake.Bonded
+		}
+		if amount > 0 {
+			stake.Bonded -= amount
+			stake.Unbonding = append(stake.Unbonding, Unbonding{Amount: amount, Height: s.Height + 1})
+		}
+	case WithdrawTx:
+		account := s.Accounts[address]
+		account.Balance += stake.withdraw(tx.Body.Amount)
+		s.Accounts[address] = account
+	case SlashTx:
+		if err := validateStakingTx(tx); err != nil || s.isSlashed(*tx.Body.Evidence) {
+			return
+		}
+		s.Slashed = append(s.Slashed, tx.Body.Evidence.Hash())
+		address = tx.Body.Recipient
+		stake = Stake{}
+	}
+
+	s.setStake(address, stake)
+}
+
+func (s State) isSlashed(evidence DoubleSignEvidence) bool {
+	hash := evidence.Hash()
+	for _, slashed := range s.Slashed {
+		if slashed == hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s State) slashedBytes() []byte {
+	data, _ := json.Marshal(s.Slashed)
+	return crypto.HashData(data)
+}
+
+func (s State) stakesBytes() []byte {
+	data, _ := json.Marshal(s.Stakes)
+	return crypto.HashData(data)
+}
+
+// verifyStakingTx checks the staking transaction against the state of an
+// engine bonding stakes for the given unbonding period.
+func verifyStakingTx(state State, tx Transaction, unbondingPeriod int64) error {
+	if err := validateStakingTx(tx); err != nil {
+		return err
+	}
+
+	stake := state.GetStake(tx.Body.Sender)
+	height := state.Height + 1
+
+	switch tx.Body.Type {
+	case UnstakeTx:
+		if tx.Body.Amount > stake.Bonded {
+			return utils.GenericError{Msg: fmt.Sprintf("unstaking %v out of %v bonded", tx.Body.Amount, stake.Bonded)}
+		}
+	case WithdrawTx:
+		if matured := stake.Matured(height, unbondingPeriod); tx.Body.Amount > matured {
+			return utils.GenericError{Msg: fmt.Sprintf("withdrawing %v out of %v unbonded", tx.Body.Amount, matured)}
+		}
+	case SlashTx:
+		if state.isSlashed(*tx.Body.Evidence) {
+			return utils.GenericError{Msg: "the evidence has already been slashed for"}
+		}
+		if state.GetStake(tx.Body.Recipient).isEmpty() {
+			return utils.GenericError{Msg: "the offender has no stake to slash"}
+		}
+	}
+
+	return nil
+}
+
+// rejectStakingTx is the transaction check of engines without staking.
+func rejectStakingTx(tx Transaction) error {
+	if tx.isStaking() {
+		return utils.GenericError{Msg: "staking transactions are not supported by the consensus"}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/antavelos/blockchain/src/pkg/smt"
+)
+
+type Account struct {
+	Balance float64 `json:"balance"`
+	Nonce   uint64  `json:"nonce"`
+}
+
+// Bytes is the fixed size encoding of the account committed in the state
+// tree.
+func (a Account) Bytes() []byte {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[:8], math.Float64bits(a.Balance))
+	binary.BigEndian.PutUint64(data[8:], a.Nonce)
+
+	return data
+}
+
+// State is the account state after the block of the given height. Votes
+// holds the validator votes of the governance transactions in the order they
+// were included, Stakes the stakes of the staking transactions and Slashed
+// the hashes of the double signing evidence already slashed for. Reveal and
+// Timestamp are the reveal and the timestamp of the block of the given
+// height. They derive from the block and are not committed.
+type State struct {
+	Height    int64              `json:"height"`
+	Accounts  map[string]Account `json:"accounts"`
+	Votes     []ValidatorVote    `json:"votes,omitempty"`
+	Stakes    map[string]Stake   `json:"stakes,omitempty"`
+	Slashed   []string           `json:"slashed,omitempty"`
+	Reveal    string             `json:"reveal,omitempty"`
+	Timestamp int64              `json:"timestamp,omitempty"`
+}
+
+func NewState() State {
+	return State{Accounts: make(map[string]Account)}
+}
+
+func (s State) Copy() State {
+	state := State{Height: s.Height, Accounts: make(map[string]Account, len(s.Accounts)), Reveal: s.Reveal, Timestamp: s.Timestamp}
+	for address, account := range s.Accounts {
+		state.Accounts[address] = account
+	}
+	state.Votes = append(state.Votes, s.Votes...)
+	state.Slashed = append(state.Slashed, s.Slashed...)
+	for address, stake := range s.Stakes {
+		state.setStake(address, stake.copy())
+	}
+
+	return state
+}
+
+func (s State) GetAccount(address string) Account {
+	return s.Accounts[address]
+}
+
+func (s *State) ApplyTx(tx Transaction) {
+	if s.Accounts == nil {
+		s.Accounts = make(map[string]Account)
+	}
+
+	if !tx.isCoinbase() {
+		sender := s.Accounts[tx.Body.Sender]
+		sender.Balance -= tx.Body.debit()
+		sender.Nonce++
+		s.Accounts[tx.Body.Sender] = sender
+	}
+
+	switch {
+	case tx.isGovernance():
+		s.Votes = append(s.Votes, ValidatorVote{Voter: tx.Body.Sender, Type: tx.Body.Type, Validator: tx.Body.Recipient})
+	case tx.isStaking():
+		s.applyStakingTx(tx)
+	}
+
+	if tx.Body.Type == "" {
+		recipient := s.Accounts[tx.Body.Recipient]
+		recipient.Balance += tx.Body.Amount
+		s.Accounts[tx.Body.Recipient] = recipient
+	}
+}
+
+func (s *State) ApplyBlock(block Block) {
+	for _, tx := range block.Txs {
+		s.ApplyTx(tx)
+	}
+	s.Height = block.Idx
+	s.Reveal = block.Reveal
+	s.Timestamp = block.Timestamp
+}
+
+func (s State) tree() *smt.Tree {
+	tree := smt.New()
+	for address, account := range s.Accounts {
+		tree.Set([]byte(address), account.Bytes())
+	}
+
+	if len(s.Votes) > 0 {
+		tree.Set([]byte(votesKey), s.votesBytes())
+	}
+
+	if len(s.Stakes) > 0 {
+		tree.Set([]byte(stakesKey), s.stakesBytes())
+	}
+
+	if len(s.Slashed) > 0 {
+		tree.Set([]byte(slashedKey), s.slashedBytes())
+	}
+
+	return tree
+}
+
+func (s State) Root() []byte {
+	return s.tree().Root()
+}
+
+// GetState returns the account state after the block of the given height by
+// replaying the blocks following the base state.
+func (bc *Blockchain) GetState(height int64) (State, bool) {
+	if height == bc.State.Height && bc.State.Accounts != nil {
+		return bc.State, true
+	}
+
+	if height < bc.BaseState.Height || height > bc.lastBlock().Idx {
+		return State{}, false
+	}
+
+	state := bc.BaseState.Copy()
+	for _, block := range bc.Blocks {
+		if block.Idx <= state.Height || block.Idx > height {
+			continue
+		}
+
+		if block.Pruned {
+			return State{}, false
+		}
+
+		state.ApplyBlock(block)
+	}
+
+	return state, true
+}
+
+// StateProof proves the account of an address against the state root of the
+// block header of the given height.
+type StateProof struct {
+	Address   string    `json:"address"`
+	Account   *Account  `json:"account"`
+	Height    int64     `json:"height"`
+	StateRoot []byte    `json:"stateRoot"`
+	Proof     smt.Proof `json:"proof"`
+}
+
+func (s State) Prove(address string) StateProof {
+	tree := s.tree()
+
+	proof := StateProof{
+		Address:   address,
+		Height:    s.Height,
+		StateRoot: tree.Root(),
+		Proof:     tree.Prove([]byte(address)),
+	}
+
+	if account, ok := s.Accounts[address]; ok {
+		proof.Account = &account
+	}
+
+	return proof
+}
+
+// VerifyStateProof checks the proof against the state root of a trusted
+// header. A nil account proves that the address has no state.
+func VerifyStateProof(header BlockHeader, proof StateProof) bool {
+	if header.Idx != proof.Height {
+		return false
+	}
+
+	var value []byte
+	if proof.Account != nil {
+		value = proof.Account.Bytes()
+	}
+
+	return smt.VerifyProof(header.StateRoot, []byte(proof.Address), value, proof.Proof)
+}
@@ -0,0 +1,137 @@
+package blockchain
+
+import "testing"
+
+func TestAddBlockVerifiesStateRoot(t *testing.T) {
+	blockchain := NewBlockchain()
+	blockchain.TxPool = []Transaction{
+		{Id: "tx1", Body: TransactionBody{Sender: "0", Recipient: "John", Amount: 5.0}},
+	}
+
+	block, _ := blockchain.NewBlock(10)
+
+	tampered := block
+	tampered.StateRoot = NewState().Root()
+	if err := blockchain.AddBlock(tampered); err == nil {
+		t.Errorf("Expected block with wrong state root to be rejected")
+	}
+
+	if err := blockchain.AddBlock(block); err != nil {
+		t.Fatalf("Expected block to be added but got: %v", err)
+	}
+
+	if balance := blockchain.GetConfirmedBalance("John"); balance != 5.0 {
+		t.Errorf("Expected balance 5.0 but got %v", balance)
+	}
+
+	if !blockchain.IsValid() {
+		t.Errorf("Expected blockchain to be valid")
+	}
+}
+
+func TestStateProof(t *testing.T) {
+	blockchain := NewBlockchain()
+	blockchain.TxPool = []Transaction{
+		{Id: "tx1", Body: TransactionBody{Sender: "0", Recipient: "John", Amount: 5.0}},
+	}
+	block, _ := blockchain.NewBlock(10)
+	blockchain.AddBlock(block)
+
+	proof := blockchain.State.Prove("John")
+	if !VerifyStateProof(block.BlockHeader, proof) {
+		t.Errorf("Expected state proof to verify")
+	}
+
+	proof.Account.Balance = 6.0
+	if VerifyStateProof(block.BlockHeader, proof) {
+		t.Errorf("Expected tampered state proof to fail")
+	}
+
+	if !VerifyStateProof(block.BlockHeader, blockchain.State.Prove("Jane")) {
+		t.Errorf("Expected absence proof to verify")
+	}
+}
+
+func TestNewBlockchainFromSnapshot(t *testing.T) {
+	blockchain := NewBlockchain()
+	for _, id := range []string{"tx1", "tx2", "tx3"} {
+		blockchain.TxPool = []Transaction{
+			{Id: id, Body: TransactionBody{Sender: "0", Recipient: "John", Amount: 5.0}},
+		}
+		block, _ := blockchain.NewBlock(10)
+		blockchain.AddBlock(block)
+	}
+
+	snapshot, err := blockchain.NewSnapshot(2)
+	if err != nil {
+		t.Fatalf("Expected snapshot but got: %v", err)
+	}
+
+	pow := NewProofOfWork(0)
+	restored, err := NewBlockchainFromSnapshot(pow, blockchain.Headers(0), snapshot, blockchain.Blocks[2:])
+	if err != nil {
+		t.Fatalf("Expected blockchain to be restored but got: %v", err)
+	}
+
+	if balance := restored.GetConfirmedBalance("John"); balance != 15.0 {
+		t.Errorf("Expected balance 15.0 but got %v", balance)
+	}
+
+	if !restored.IsValid() {
+		t.Errorf("Expected restored blockchain to be valid")
+	}
+
+	// the blocks after the snapshot have to match the headers and the consensus
+	fork := blockchain.Blocks[2]
+	fork.Txs = []Transaction{{Id: "fork", Body: TransactionBody{Sender: "0", Recipient: "Jane", Amount: 100.0}}}
+	fork.MerkleRoot = MerkleRoot(fork.Txs)
+	if _, err := NewBlockchainFromSnapshot(pow, blockchain.Headers(0), snapshot, []Block{fork}); err == nil {
+		t.Errorf("Expected a block other than its header to be rejected but got %v", err)
+	}
+
+	if _, err := NewBlockchainFromSnapshot(NewProofOfWork(8), blockchain.Headers(0), snapshot, blockchain.Blocks[2:]); err == nil {
+		t.Errorf("Expected a block without proof of work to be rejected but got %v", err)
+	}
+
+	snapshot.State.Accounts["John"] = Account{Balance: 100.0}
+	if _, err := NewBlockchainFromSnapshot(pow, blockchain.Headers(0), snapshot, nil); err == nil {
+		t.Errorf("Expected tampered snapshot to be rejected")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	blockchain := NewBlockchain()
+	for _, id := range []string{"tx1", "tx2", "tx3"} {
+		blockchain.TxPool = []Transaction{
+			{Id: id, Body: TransactionBody{Sender: "0", Recipient: "John", Amount: 5.0}},
+		}
+		block, _ := blockchain.NewBlock(10)
+		blockchain.AddBlock(block)
+	}
+
+	if !blockchain.Prune(1) {
+		t.Fatalf("Expected blockchain to be pruned")
+	}
+
+	for _, block := range blockchain.Blocks[:len(blockchain.Blocks)-1] {
+		if !block.Pruned || len(block.Txs) > 0 {
+			t.Errorf("Expected block %v to be pruned", block.Idx)
+		}
+	}
+
+	if blockchain.LastBlock().Pruned {
+		t.Errorf("Expected last block to be kept")
+	}
+
+	if !blockchain.IsValid() {
+		t.Errorf("Expected pruned blockchain to be valid")
+	}
+
+	if balance := blockchain.GetConfirmedBalance("John"); balance != 15.0 {
+		t.Errorf("Expected balance 15.0 but got %v", balance)
+	}
+
+	if blockchain.Prune(1) {
+		t.Errorf("Expected nothing more to prune")
+	}
+}
@@ -0,0 +1,72 @@
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// TimestampRules bound the timestamp of a block: it has to be later than the
+// median timestamp of the MedianSpan blocks before it and no more than
+// MaxFutureDrift ahead of the network-adjusted time.
+type TimestampRules struct {
+	MedianSpan     int
+	MaxFutureDrift time.Duration
+}
+
+// MedianTimePast is the median timestamp of the last span blocks up to the
+// given height, or 0 when there are none.
+func (bc *Blockchain) MedianTimePast(height int64, span int) int64 {
+	end := sort.Search(len(bc.Blocks), func(i int) bool { return bc.Blocks[i].Idx > height })
+
+	timestamps := []int64{}
+	for i := end - 1; i >= 0 && len(timestamps) < span; i-- {
+		timestamps = append(timestamps, bc.Blocks[i].Timestamp)
+	}
+
+	if len(timestamps) == 0 {
+		return 0
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return timestamps[len(timestamps)/2]
+}
+
+// Check checks the timestamp of the block following the blockchain's tip
+// against the network-adjusted time now.
+func (r TimestampRules) Check(bc *Blockchain, block Block, now time.Time) error {
+	if median := bc.MedianTimePast(block.Idx-1, r.MedianSpan); block.Timestamp <= median {
+		return utils.GenericError{Msg: fmt.Sprintf("block %v timestamp is not later than the median of the previous blocks", block.Idx)}
+	}
+
+	if limit := now.Add(r.MaxFutureDrift).UnixMilli(); block.Timestamp > limit {
+		return utils.GenericError{Msg: fmt.Sprintf("block %v timestamp is too far in the future", block.Idx)}
+	}
+
+	return nil
+}
+
+// CheckChain checks the timestamps of all the blocks of the blockchain.
+func (r TimestampRules) CheckChain(bc *Blockchain, now time.Time) error {
+	for _, block := range bc.Blocks {
+		if err := r.Check(bc, block, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NextTimestamp is the timestamp of a new block on top of the blockchain: the
+// network-adjusted time now, unless it is not later than the median.
+func (r TimestampRules) NextTimestamp(bc *Blockchain, now time.Time) int64 {
+	timestamp := now.UnixMilli()
+	if median := bc.MedianTimePast(bc.lastBlock().Idx, r.MedianSpan); timestamp <= median {
+		timestamp = median + 1
+	}
+
+	return timestamp
+}
@@ -0,0 +1,53 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+)
+
+func timestampedBlockchain(timestamps ...int64) *Blockchain {
+	blockchain := &Blockchain{}
+	for i, timestamp := range timestamps {
+		blockchain.Blocks = append(blockchain.Blocks, Block{BlockHeader: BlockHeader{Idx: int64(i + 1), Timestamp: timestamp}})
+	}
+
+	return blockchain
+}
+
+func TestMedianTimePast(t *testing.T) {
+	blockchain := timestampedBlockchain(10, 50, 20, 40, 30)
+
+	if median := blockchain.MedianTimePast(5, 3); median != 30 {
+		t.Errorf("Expected 30 but got %v", median)
+	}
+
+	if median := blockchain.MedianTimePast(3, 11); median != 20 {
+		t.Errorf("Expected 20 but got %v", median)
+	}
+
+	if median := blockchain.MedianTimePast(0, 11); median != 0 {
+		t.Errorf("Expected 0 but got %v", median)
+	}
+}
+
+func TestTimestampRulesCheck(t *testing.T) {
+	rules := TimestampRules{MedianSpan: 3, MaxFutureDrift: 15 * time.Second}
+	now := time.UnixMilli(100_000)
+	blockchain := timestampedBlockchain(10, 50, 20, 40, 30)
+
+	if err := rules.Check(blockchain, Block{BlockHeader: BlockHeader{Idx: 6, Timestamp: 30}}, now); err == nil {
+		t.Errorf("Expected a timestamp not later than the median to be rejected but got %v", err)
+	}
+
+	if err := rules.Check(blockchain, Block{BlockHeader: BlockHeader{Idx: 6, Timestamp: 31}}, now); err != nil {
+		t.Errorf("Expected a timestamp later than the median to be valid but got %v", err)
+	}
+
+	if err := rules.Check(blockchain, Block{BlockHeader: BlockHeader{Idx: 6, Timestamp: 115_001}}, now); err == nil {
+		t.Errorf("Expected a timestamp too far in the future to be rejected but got %v", err)
+	}
+
+	if timestamp := rules.NextTimestamp(blockchain, time.UnixMilli(25)); timestamp != 31 {
+		t.Errorf("Expected 31 but got %v", timestamp)
+	}
+}
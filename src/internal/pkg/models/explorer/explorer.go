@@ -0,0 +1,154 @@
+package explorer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const DefaultPageLimit = 20
+const MaxPageLimit = 100
+
+var errInvalidLimit = utils.GenericError{Msg: "invalid limit"}
+
+type Block struct {
+	Hash       string `json:"hash"`
+	Height     int64  `json:"height"`
+	Timestamp  int64  `json:"timestamp"`
+	PrevHash   string `json:"prevHash"`
+	MerkleRoot string `json:"merkleRoot"`
+	StateRoot  string `json:"stateRoot"`
+	Nonce      int64  `json:"nonce"`
+	TxCount    int    `json:"txCount"`
+}
+
+func NewBlock(block bc.Block) Block {
+	return Block{
+		Hash:       block.HashString(),
+		Height:     block.Idx,
+		Timestamp:  block.Timestamp,
+		PrevHash:   hex.EncodeToString(block.PrevHash),
+		MerkleRoot: hex.EncodeToString(block.MerkleRoot),
+		StateRoot:  hex.EncodeToString(block.StateRoot),
+		Nonce:      block.Nonce,
+		TxCount:    len(block.Txs),
+	}
+}
+
+type Transaction struct {
+	Id          string  `json:"id"`
+	Timestamp   int64   `json:"timestamp"`
+	Sender      string  `json:"sender"`
+	Recipient   string  `json:"recipient"`
+	Amount      float64 `json:"amount"`
+	Fee         float64 `json:"fee,omitempty"`
+	Signature   string  `json:"signature"`
+	Status      string  `json:"status"`
+	BlockHash   string  `json:"blockHash,omitempty"`
+	BlockHeight int64   `json:"blockHeight,omitempty"`
+}
+
+// NewTransaction builds the view of a transaction. An empty block denotes a
+// pending transaction.
+func NewTransaction(tx bc.Transaction, block bc.Block) Transaction {
+	view := Transaction{
+		Id:        tx.Id,
+		Timestamp: tx.Timestamp,
+		Sender:    tx.Body.Sender,
+		Recipient: tx.Body.Recipient,
+		Amount:    tx.Body.Amount,
+		Fee:       tx.Body.Fee,
+		Signature: tx.Signature,
+		Status:    string(bc.TxPending),
+	}
+
+	if block.Idx > 0 {
+		view.Status = string(bc.TxConfirmed)
+		view.BlockHash = block.HashString()
+		view.BlockHeight = block.Idx
+	}
+
+	return view
+}
+
+// Balance is the balance of an address along with the nonce its next
+// transaction has to be signed with.
+type Balance struct {
+	Address   string  `json:"address"`
+	Confirmed float64 `json:"confirmed"`
+	Pending   float64 `json:"pending"`
+	NextNonce uint64  `json:"nextNonce"`
+}
+
+// Page is a slice of a longer list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Paginate returns the page of at most limit items starting at the position
+// the cursor points to.
+func Paginate[T any](items []T, cursor string, limit int) (Page[T], error) {
+	if limit < 1 {
+		return Page[T]{}, errInvalidLimit
+	}
+
+	offset, err := DecodeCursor(cursor)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	page := Page[T]{Items: append([]T{}, items[offset:end]...)}
+	if end < len(items) {
+		page.NextCursor = EncodeCursor(end)
+	}
+
+	return page, nil
+}
+
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, utils.GenericError{Msg: "invalid cursor"}
+	}
+
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, utils.GenericError{Msg: "invalid cursor"}
+	}
+
+	return offset, nil
+}
+
+func ParseLimit(limit string) (int, error) {
+	if limit == "" {
+		return DefaultPageLimit, nil
+	}
+
+	value, err := strconv.Atoi(limit)
+	if err != nil || value < 1 || value > MaxPageLimit {
+		return 0, errInvalidLimit
+	}
+
+	return value, nil
+}
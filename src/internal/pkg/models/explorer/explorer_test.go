@@ -0,0 +1,149 @@
+package explorer
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 20, 12345} {
+		decoded, err := DecodeCursor(EncodeCursor(offset))
+		if err != nil || decoded != offset {
+			t.Errorf("Expected offset %v but got %v, %v", offset, decoded, err)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		cursor   string
+		expected int
+		valid    bool
+	}{
+		{"empty", "", 0, true},
+		{"offset", EncodeCursor(7), 7, true},
+		{"not base64", "not a cursor!", 0, false},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("7")), 0, false},
+		{"not a number", base64.RawURLEncoding.EncodeToString([]byte("seven")), 0, false},
+		{"negative", base64.RawURLEncoding.EncodeToString([]byte("-1")), 0, false},
+		{"overflowing", base64.RawURLEncoding.EncodeToString([]byte("99999999999999999999")), 0, false},
+	}
+
+	for _, test := range tests {
+		offset, err := DecodeCursor(test.cursor)
+		if test.valid && (err != nil || offset != test.expected) {
+			t.Errorf("%v: expected offset %v but got %v, %v", test.name, test.expected, offset, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: expected the cursor to be refused but got %v", test.name, offset)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		limit    string
+		expected int
+		valid    bool
+	}{
+		{"", DefaultPageLimit, true},
+		{"1", 1, true},
+		{"100", MaxPageLimit, true},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"101", 0, false},
+		{"ten", 0, false},
+	}
+
+	for _, test := range tests {
+		limit, err := ParseLimit(test.limit)
+		if test.valid && (err != nil || limit != test.expected) {
+			t.Errorf("Expected limit %v of '%v' but got %v, %v", test.expected, test.limit, limit, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("Expected limit '%v' to be refused but got %v", test.limit, limit)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name     string
+		cursor   string
+		limit    int
+		expected []int
+		next     string
+		valid    bool
+	}{
+		{"first page", "", 2, []int{1, 2}, EncodeCursor(2), true},
+		{"middle page", EncodeCursor(2), 2, []int{3, 4}, EncodeCursor(4), true},
+		{"last page", EncodeCursor(4), 2, []int{5}, "", true},
+		{"exact last page", EncodeCursor(3), 2, []int{4, 5}, "", true},
+		{"limit past the end", "", 10, []int{1, 2, 3, 4, 5}, "", true},
+		{"offset at the end", EncodeCursor(5), 2, []int{}, "", true},
+		{"offset past the end", EncodeCursor(50), 2, []int{}, "", true},
+		{"zero limit", "", 0, nil, "", false},
+		{"negative limit", "", -1, nil, "", false},
+		{"invalid cursor", "???", 2, nil, "", false},
+	}
+
+	for _, test := range tests {
+		page, err := Paginate(items, test.cursor, test.limit)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%v: expected an error but got %v", test.name, page)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v: expected a page but got %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(page.Items, test.expected) || page.NextCursor != test.next {
+			t.Errorf("%v: expected %v, '%v' but got %v, '%v'", test.name, test.expected, test.next, page.Items, page.NextCursor)
+		}
+	}
+}
+
+func TestPaginateWalksEveryItemOnce(t *testing.T) {
+	items := make([]int, 47)
+	for i := range items {
+		items[i] = i
+	}
+
+	for _, limit := range []int{1, 5, 20, 47, 100} {
+		walked := []int{}
+		pages := 0
+
+		cursor := ""
+		for {
+			page, err := Paginate(items, cursor, limit)
+			if err != nil {
+				t.Fatalf("Expected a page but got %v", err)
+			}
+			if pages++; pages > len(items) {
+				t.Fatalf("Expected the pages of limit %v to end", limit)
+			}
+
+			walked = append(walked, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		if !reflect.DeepEqual(walked, items) {
+			t.Errorf("Expected pages of limit %v to walk every item once but got %v", limit, walked)
+		}
+
+		if expected := (len(items) + limit - 1) / limit; pages != expected {
+			t.Errorf("Expected %v pages of limit %v but got %v", expected, limit, pages)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package index
+
+import (
+	"encoding/json"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+)
+
+type TxLocation struct {
+	BlockHash string `json:"blockHash"`
+	BlockIdx  int64  `json:"blockIdx"`
+	Position  int    `json:"position"`
+}
+
+// Index keeps secondary indexes over the blocks of the blockchain. It is
+// maintained as blocks get connected to or disconnected from the tip.
+type Index struct {
+	BlockHashes []string              `json:"blockHashes"`
+	Heights     map[string]int64      `json:"heights"`
+	Txs         map[string]TxLocation `json:"txs"`
+	Addresses   map[string][]string   `json:"addresses"`
+	Balances    map[string]float64    `json:"balances"`
+}
+
+func NewIndex() *Index {
+	return &Index{
+		BlockHashes: []string{},
+		Heights:     make(map[string]int64),
+		Txs:         make(map[string]TxLocation),
+		Addresses:   make(map[string][]string),
+		Balances:    make(map[string]float64),
+	}
+}
+
+func Unmarshal(data []byte) (*Index, error) {
+	index := NewIndex()
+	if len(data) == 0 {
+		return index, nil
+	}
+
+	err := json.Unmarshal(data, index)
+
+	return index, err
+}
+
+func Rebuild(blocks []bc.Block) *Index {
+	index := NewIndex()
+	for _, block := range blocks {
+		index.ConnectBlock(block)
+	}
+
+	return index
+}
+
+func (i *Index) Tip() string {
+	if len(i.BlockHashes) == 0 {
+		return ""
+	}
+
+	return i.BlockHashes[len(i.BlockHashes)-1]
+}
+
+func (i *Index) ConnectBlock(block bc.Block) {
+	hash := block.HashString()
+
+	i.BlockHashes = append(i.BlockHashes, hash)
+	i.Heights[hash] = block.Idx
+
+	for position, tx := range block.Txs {
+		i.Txs[tx.Id] = TxLocation{BlockHash: hash, BlockIdx: block.Idx, Position: position}
+
+		for _, address := range txAddresses(tx) {
+			i.Addresses[address] = append(i.Addresses[address], tx.Id)
+			i.Balances[address] += tx.BalanceChange(address)
+		}
+	}
+}
+
+// DisconnectBlock reverts the changes of ConnectBlock. Only the tip block can
+// be disconnected.
+func (i *Index) DisconnectBlock(block bc.Block) {
+	hash := block.HashString()
+	if hash != i.Tip() {
+		return
+	}
+
+	i.BlockHashes = i.BlockHashes[:len(i.BlockHashes)-1]
+	delete(i.Heights, hash)
+
+	for j := len(block.Txs) - 1; j >= 0; j-- {
+		tx := block.Txs[j]
+		delete(i.Txs, tx.Id)
+
+		for _, address := range txAddresses(tx) {
+			i.Balances[address] -= tx.BalanceChange(address)
+			i.Addresses[address] = removeLast(i.Addresses[address], tx.Id)
+
+			if len(i.Addresses[address]) == 0 {
+				delete(i.Addresses, address)
+				delete(i.Balances, address)
+			}
+		}
+	}
+}
+
+// Sync brings the index from the old to the new chain by disconnecting the
+// old blocks after the fork point and connecting the new ones. The index is
+// rebuilt if it does not reflect the old chain.
+func (i *Index) Sync(oldBlocks []bc.Block, newBlocks []bc.Block) *Index {
+	if len(oldBlocks) != len(i.BlockHashes) || (len(oldBlocks) > 0 && oldBlocks[len(oldBlocks)-1].HashString() != i.Tip()) {
+		return Rebuild(newBlocks)
+	}
+
+	fork := 0
+	for fork < len(oldBlocks) && fork < len(newBlocks) && oldBlocks[fork].HashString() == newBlocks[fork].HashString() {
+		fork++
+	}
+
+	for j := len(oldBlocks) - 1; j >= fork; j-- {
+		i.DisconnectBlock(oldBlocks[j])
+	}
+
+	for _, block := range newBlocks[fork:] {
+		i.ConnectBlock(block)
+	}
+
+	return i
+}
+
+func (i *Index) GetHeight(blockHash string) (int64, bool) {
+	height, ok := i.Heights[blockHash]
+	return height, ok
+}
+
+func (i *Index) GetTxLocation(txId string) (TxLocation, bool) {
+	location, ok := i.Txs[txId]
+	return location, ok
+}
+
+func (i *Index) HasTx(txId string) bool {
+	_, ok := i.Txs[txId]
+	return ok
+}
+
+func (i *Index) GetAddressTxIds(address string) []string {
+	return i.Addresses[address]
+}
+
+func (i *Index) GetBalance(address string) float64 {
+	return i.Balances[address]
+}
+
+func txAddresses(tx bc.Transaction) []string {
+	if tx.Body.Sender == tx.Body.Recipient {
+		return []string{tx.Body.Sender}
+	}
+
+	return []string{tx.Body.Sender, tx.Body.Recipient}
+}
+
+func removeLast(ids []string, id string) []string {
+	for j := len(ids) - 1; j >= 0; j-- {
+		if ids[j] == id {
+			return append(ids[:j], ids[j+1:]...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,53 @@
+package index
+
+import (
+	"testing"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+)
+
+func newTestBlock(idx int64, prev bc.Block, txs ...bc.Transaction) bc.Block {
+	return bc.Block{BlockHeader: bc.BlockHeader{Idx: idx, PrevHash: prev.Hash(), MerkleRoot: bc.MerkleRoot(txs)}, Txs: txs}
+}
+
+func newTestTx(id string, sender string, recipient string, amount float64) bc.Transaction {
+	return bc.Transaction{Id: id, Body: bc.TransactionBody{Sender: sender, Recipient: recipient, Amount: amount}}
+}
+
+func TestIndexSync(t *testing.T) {
+	genesis := bc.Block{BlockHeader: bc.BlockHeader{Idx: 1}}
+	block2 := newTestBlock(2, genesis, newTestTx("tx1", "0", "alice", 5))
+	block3 := newTestBlock(3, block2, newTestTx("tx2", "alice", "bob", 2))
+	forkBlock3 := newTestBlock(3, block2, newTestTx("tx3", "alice", "carol", 1))
+
+	oldBlocks := []bc.Block{genesis, block2, block3}
+	index := Rebuild(oldBlocks)
+
+	if index.GetBalance("bob") != 2 {
+		t.Errorf("Expected bob's balance to be 2 but got %v", index.GetBalance("bob"))
+	}
+
+	newBlocks := []bc.Block{genesis, block2, forkBlock3}
+	index = index.Sync(oldBlocks, newBlocks)
+
+	if _, found := index.GetTxLocation("tx2"); found {
+		t.Errorf("Expected tx2 to be disconnected")
+	}
+
+	location, found := index.GetTxLocation("tx3")
+	if !found || location.BlockIdx != 3 {
+		t.Errorf("Expected tx3 to be located in block 3 but got %v", location)
+	}
+
+	if _, found := index.Addresses["bob"]; found {
+		t.Errorf("Expected bob to be removed from the address index")
+	}
+
+	if index.GetBalance("alice") != 4 {
+		t.Errorf("Expected alice's balance to be 4 but got %v", index.GetBalance("alice"))
+	}
+
+	if height, _ := index.GetHeight(forkBlock3.HashString()); height != 3 {
+		t.Errorf("Expected the fork block height to be 3 but got %v", height)
+	}
+}
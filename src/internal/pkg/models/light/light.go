@@ -0,0 +1,182 @@
+package light
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// VerifiedTx is a transaction of a watched address whose inclusion in a block
+// has been verified through a Merkle proof.
+type VerifiedTx struct {
+	Tx        bc.Transaction `json:"tx"`
+	BlockHash string         `json:"blockHash"`
+	BlockIdx  int64          `json:"blockIdx"`
+}
+
+// Chain is the state kept by a light node: the block headers, the watched
+// addresses and their verified transactions.
+type Chain struct {
+	Headers []bc.BlockHeader `json:"headers"`
+	Watched []string         `json:"watched"`
+	Txs     []VerifiedTx     `json:"txs"`
+}
+
+func Unmarshal(data []byte) (chain Chain, err error) {
+	if len(data) == 0 {
+		return Chain{}, nil
+	}
+
+	err = json.Unmarshal(data, &chain)
+	return
+}
+
+func (c *Chain) Tip() *bc.BlockHeader {
+	if len(c.Headers) == 0 {
+		return nil
+	}
+
+	return &c.Headers[len(c.Headers)-1]
+}
+
+func (c *Chain) Height() int64 {
+	tip := c.Tip()
+	if tip == nil {
+		return 0
+	}
+
+	return tip.Idx
+}
+
+func (c *Chain) GetHeader(idx int64) (bc.BlockHeader, bool) {
+	for _, header := range c.Headers {
+		if header.Idx == idx {
+			return header, true
+		}
+	}
+	return bc.BlockHeader{}, false
+}
+
+// Extend appends headers that follow the current tip. The first headers of
+// the chain have to start from the expected genesis.
+func (c *Chain) Extend(headers []bc.BlockHeader, consensus bc.Consensus, genesisHash string) error {
+	if len(c.Headers) == 0 {
+		if err := c.checkGenesis(headers, genesisHash); err != nil {
+			return err
+		}
+	}
+
+	if err := bc.ValidateHeaders(c.Tip(), headers, consensus); err != nil {
+		return err
+	}
+
+	c.Headers = append(c.Headers, headers...)
+
+	return nil
+}
+
+// Replace switches to a longer header chain of the same genesis and forgets
+// the transactions of the blocks that are no longer part of it.
+func (c *Chain) Replace(headers []bc.BlockHeader, consensus bc.Consensus, genesisHash string) error {
+	if len(headers) <= len(c.Headers) {
+		return utils.GenericError{Msg: "headers chain is not longer than the local one"}
+	}
+
+	if err := c.checkGenesis(headers, genesisHash); err != nil {
+		return err
+	}
+
+	if err := bc.ValidateHeaders(nil, headers, consensus); err != nil {
+		return err
+	}
+
+	c.Headers = headers
+	c.Txs = utils.Filter(c.Txs, func(vtx VerifiedTx) bool {
+		header, found := c.GetHeader(vtx.BlockIdx)
+		return found && header.HashString() == vtx.BlockHash
+	})
+
+	return nil
+}
+
+// checkGenesis checks that the headers start from the genesis of the given
+// hash or, when none is given, from the genesis of the local headers.
+func (c *Chain) checkGenesis(headers []bc.BlockHeader, genesisHash string) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	if headers[0].Idx != 1 {
+		return utils.GenericError{Msg: "headers chain does not start from the genesis"}
+	}
+
+	if genesisHash == "" && len(c.Headers) > 0 {
+		genesisHash = c.Headers[0].HashString()
+	}
+
+	if genesisHash != "" && headers[0].HashString() != genesisHash {
+		return utils.GenericError{Msg: fmt.Sprintf("unexpected genesis %v", headers[0].HashString())}
+	}
+
+	return nil
+}
+
+func (c *Chain) Watch(address string) {
+	if !c.IsWatched(address) {
+		c.Watched = append(c.Watched, address)
+	}
+}
+
+func (c *Chain) IsWatched(address string) bool {
+	for _, watched := range c.Watched {
+		if watched == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Chain) HasTx(txId string) bool {
+	for _, vtx := range c.Txs {
+		if vtx.Tx.Id == txId {
+			return true
+		}
+	}
+	return false
+}
+
+// AddProvenTx verifies the inclusion proof against the local header of the
+// same height and keeps the transaction.
+func (c *Chain) AddProvenTx(proof bc.InclusionProof) error {
+	header, found := c.GetHeader(proof.Header.Idx)
+	if !found || header.HashString() != proof.Header.HashString() {
+		return utils.GenericError{Msg: "proof header is not part of the local headers chain"}
+	}
+
+	if !bc.VerifyInclusionProof(proof) {
+		return utils.GenericError{Msg: "invalid inclusion proof"}
+	}
+
+	if !c.HasTx(proof.Tx.Id) {
+		c.Txs = append(c.Txs, VerifiedTx{Tx: proof.Tx, BlockHash: header.HashString(), BlockIdx: header.Idx})
+	}
+
+	return nil
+}
+
+func (c *Chain) History(address string) []VerifiedTx {
+	return utils.Filter(c.Txs, func(vtx VerifiedTx) bool {
+		return vtx.Tx.Body.Sender == address || vtx.Tx.Body.Recipient == address
+	})
+}
+
+func (c *Chain) Balance(address string) float64 {
+	balance := 0.0
+	for _, vtx := range c.History(address) {
+		balance += vtx.Tx.BalanceChange(address)
+	}
+
+	return balance
+}
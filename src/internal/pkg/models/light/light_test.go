@@ -0,0 +1,200 @@
+package light
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+)
+
+var testConsensus = bc.NewProofOfWork(1)
+
+// mineBlocks adds n blocks to the blockchain, each one paying a coinbase
+// transaction of the given prefix to John.
+func mineBlocks(t *testing.T, blockchain *bc.Blockchain, n int, prefix string) {
+	for i := 0; i < n; i++ {
+		coinbase := []bc.Transaction{{
+			Id:   fmt.Sprintf("%v-%v", prefix, len(blockchain.Blocks)+1),
+			Body: bc.TransactionBody{Sender: "0", Recipient: "John", Amount: 1},
+		}}
+
+		block, err := blockchain.AssembleBlock(nil, bc.FIFOPolicy{}, bc.BlockLimits{MaxTxs: 10, MaxBytes: bc.UnlimitedBytes}, coinbase)
+		if err != nil {
+			t.Fatalf("Expected block but got: %v", err)
+		}
+
+		block.BlockHeader, err = testConsensus.Seal(context.Background(), block.BlockHeader, bc.SealOptions{Workers: 1})
+		if err != nil {
+			t.Fatalf("Expected sealed block but got: %v", err)
+		}
+
+		if err := blockchain.AddBlock(block); err != nil {
+			t.Fatalf("Expected block to be added but got: %v", err)
+		}
+	}
+}
+
+func forkBlockchain(t *testing.T, blockchain *bc.Blockchain) *bc.Blockchain {
+	data, _ := json.Marshal(blockchain)
+
+	fork, err := bc.UnmarshalBlockchain(data)
+	if err != nil {
+		t.Fatalf("Expected blockchain but got: %v", err)
+	}
+
+	return &fork
+}
+
+func proveTx(t *testing.T, blockchain *bc.Blockchain, idx int64, txId string) bc.InclusionProof {
+	proof, err := bc.NewInclusionProof(blockchain.Blocks[idx-1], txId)
+	if err != nil {
+		t.Fatalf("Expected proof but got: %v", err)
+	}
+
+	return proof
+}
+
+func TestChainExtend(t *testing.T) {
+	blockchain := bc.NewBlockchain()
+	mineBlocks(t, blockchain, 4, "main")
+	headers := blockchain.Headers(1)
+	genesisHash := headers[0].HashString()
+
+	chain := Chain{}
+	if err := chain.Extend(headers[1:3], testConsensus, ""); err == nil {
+		t.Errorf("Expected headers not starting from the genesis to be refused but got %v", err)
+	}
+
+	if err := chain.Extend(headers[:3], testConsensus, genesisHash); err != nil {
+		t.Fatalf("Expected the headers to extend the chain but got %v", err)
+	}
+
+	if err := chain.Extend(headers[4:], testConsensus, genesisHash); err == nil {
+		t.Errorf("Expected headers not following the tip to be refused but got %v", err)
+	}
+
+	if err := chain.Extend(headers[3:], testConsensus, genesisHash); err != nil {
+		t.Errorf("Expected the headers to extend the chain but got %v", err)
+	}
+
+	if height := chain.Height(); height != 5 {
+		t.Errorf("Expected height 5 but got %v", height)
+	}
+}
+
+func TestChainExtendRefusesUnexpectedGenesis(t *testing.T) {
+	blockchain := bc.NewBlockchain()
+	mineBlocks(t, blockchain, 2, "main")
+
+	other := bc.NewBlockchain()
+	other.Blocks[0].Timestamp -= 1000
+	mineBlocks(t, other, 2, "other")
+
+	chain := Chain{}
+	err := chain.Extend(other.Headers(1), testConsensus, blockchain.Blocks[0].HashString())
+	if err == nil {
+		t.Errorf("Expected headers of another genesis to be refused but got %v", err)
+	}
+
+	if height := chain.Height(); height != 0 {
+		t.Errorf("Expected height 0 but got %v", height)
+	}
+}
+
+func TestChainReplace(t *testing.T) {
+	blockchain := bc.NewBlockchain()
+	mineBlocks(t, blockchain, 1, "main")
+	fork := forkBlockchain(t, blockchain)
+	mineBlocks(t, blockchain, 2, "main")
+	mineBlocks(t, fork, 3, "fork")
+
+	chain := Chain{}
+	if err := chain.Extend(blockchain.Headers(1), testConsensus, ""); err != nil {
+		t.Fatalf("Expected the headers to extend the chain but got %v", err)
+	}
+	for _, idx := range []int64{2, 4} {
+		if err := chain.AddProvenTx(proveTx(t, blockchain, idx, fmt.Sprintf("main-%v", idx))); err != nil {
+			t.Fatalf("Expected the proven transaction to be kept but got %v", err)
+		}
+	}
+
+	if err := chain.Replace(fork.Headers(1)[:3], testConsensus, ""); err == nil {
+		t.Errorf("Expected a chain that is not longer to be refused but got %v", err)
+	}
+
+	if err := chain.Replace(fork.Headers(1), testConsensus, ""); err != nil {
+		t.Fatalf("Expected the longer chain to replace the local one but got %v", err)
+	}
+
+	if height := chain.Height(); height != 5 {
+		t.Errorf("Expected height 5 but got %v", height)
+	}
+
+	if !chain.HasTx("main-2") || chain.HasTx("main-4") {
+		t.Errorf("Expected only the transactions of the common blocks to be kept but got %v", chain.Txs)
+	}
+}
+
+func TestChainReplaceRefusesAnotherGenesis(t *testing.T) {
+	blockchain := bc.NewBlockchain()
+	mineBlocks(t, blockchain, 2, "main")
+
+	other := bc.NewBlockchain()
+	other.Blocks[0].Timestamp -= 1000
+	mineBlocks(t, other, 4, "other")
+
+	chain := Chain{}
+	if err := chain.Extend(blockchain.Headers(1), testConsensus, ""); err != nil {
+		t.Fatalf("Expected the headers to extend the chain but got %v", err)
+	}
+
+	if err := chain.Replace(other.Headers(1), testConsensus, ""); err == nil {
+		t.Errorf("Expected a chain of another genesis to be refused but got %v", err)
+	}
+
+	if tip := chain.Tip(); tip == nil || tip.HashString() != blockchain.Blocks[2].HashString() {
+		t.Errorf("Expected the local chain to be kept but got %v", tip)
+	}
+}
+
+func TestChainWatch(t *testing.T) {
+	blockchain := bc.NewBlockchain()
+	mineBlocks(t, blockchain, 2, "main")
+
+	chain := Chain{}
+	if err := chain.Extend(blockchain.Headers(1)[:2], testConsensus, ""); err != nil {
+		t.Fatalf("Expected the headers to extend the chain but got %v", err)
+	}
+
+	chain.Watch("John")
+	chain.Watch("John")
+	if len(chain.Watched) != 1 || !chain.IsWatched("John") || chain.IsWatched("Jane") {
+		t.Errorf("Expected only John to be watched but got %v", chain.Watched)
+	}
+
+	if err := chain.AddProvenTx(proveTx(t, blockchain, 3, "main-3")); err == nil {
+		t.Errorf("Expected a proof of an unknown header to be refused but got %v", err)
+	}
+
+	proof := proveTx(t, blockchain, 2, "main-2")
+	proof.Tx.Body.Amount = 10
+	if err := chain.AddProvenTx(proof); err == nil {
+		t.Errorf("Expected a tampered proof to be refused but got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := chain.AddProvenTx(proveTx(t, blockchain, 2, "main-2")); err != nil {
+			t.Errorf("Expected the proven transaction to be kept but got %v", err)
+		}
+	}
+
+	if history := chain.History("John"); len(history) != 1 {
+		t.Errorf("Expected 1 transaction of John but got %v", len(history))
+	}
+
+	if balance := chain.Balance("John"); balance != 1 {
+		t.Errorf("Expected balance 1 but got %v", balance)
+	}
+}
@@ -0,0 +1,86 @@
+package mining
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+)
+
+// Template describes the block a miner is currently working on.
+type Template struct {
+	Height     int64  `json:"height"`
+	Timestamp  int64  `json:"timestamp"`
+	PrevHash   string `json:"prevHash"`
+	MerkleRoot string `json:"merkleRoot"`
+	TxCount    int    `json:"txCount"`
+}
+
+func NewTemplate(block bc.Block) *Template {
+	return &Template{
+		Height:     block.Idx,
+		Timestamp:  block.Timestamp,
+		PrevHash:   hex.EncodeToString(block.PrevHash),
+		MerkleRoot: hex.EncodeToString(block.MerkleRoot),
+		TxCount:    len(block.Txs),
+	}
+}
+
+// Status is the current state of a node's miner. The RewardAddress set at
+// runtime takes precedence over the configured RewardSplits. Without either
+// the node's own wallet gets rewarded.
+type Status struct {
+	Running       bool          `json:"running"`
+	Workers       int           `json:"workers"`
+	RewardAddress string        `json:"rewardAddress"`
+	RewardSplits  []RewardSplit `json:"rewardSplits,omitempty"`
+	HashRate      float64       `json:"hashRate"`
+	Template      *Template     `json:"template,omitempty"`
+	BlocksFound   int           `json:"blocksFound"`
+	StaleBlocks   int           `json:"staleBlocks"`
+}
+
+func UnmarshalStatus(data []byte) (status Status, err error) {
+	err = json.Unmarshal(data, &status)
+	return
+}
+
+// BlockTemplate lets an external miner search for the nonce of a block. The
+// nonce is written big endian at NonceOffset of HeaderBytes and the hash of
+// the result has to start with Target.
+type BlockTemplate struct {
+	Id          string           `json:"id"`
+	Header      bc.BlockHeader   `json:"header"`
+	HeaderBytes string           `json:"headerBytes"`
+	NonceOffset int              `json:"nonceOffset"`
+	Difficulty  int              `json:"difficulty"`
+	Target      string           `json:"target"`
+	Coinbase    []bc.Transaction `json:"coinbase"`
+	TxCount     int              `json:"txCount"`
+}
+
+// NewBlockTemplate builds the template of the block, which starts with
+// coinbaseCount coinbase transactions.
+func NewBlockTemplate(id string, block bc.Block, difficulty int, coinbaseCount int) BlockTemplate {
+	return BlockTemplate{
+		Id:          id,
+		Header:      block.BlockHeader,
+		HeaderBytes: hex.EncodeToString(block.Bytes()),
+		NonceOffset: bc.HeaderNonceOffset,
+		Difficulty:  difficulty,
+		Target:      hex.EncodeToString(bc.DifficultyTarget(difficulty)),
+		Coinbase:    block.Txs[:coinbaseCount],
+		TxCount:     len(block.Txs),
+	}
+}
+
+func UnmarshalBlockTemplate(data []byte) (template BlockTemplate, err error) {
+	err = json.Unmarshal(data, &template)
+	return
+}
+
+// BlockSubmission is the solution of a block template.
+type BlockSubmission struct {
+	TemplateId string `json:"templateId"`
+	Nonce      int64  `json:"nonce"`
+}
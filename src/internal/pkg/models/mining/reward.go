@@ -0,0 +1,90 @@
+package mining
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// RewardSplit sends a percentage of the block reward to an address.
+type RewardSplit struct {
+	Address    string  `json:"address"`
+	Percentage float64 `json:"percentage"`
+}
+
+// ParseRewardSplits parses comma separated address:percentage pairs. A single
+// address without a percentage gets the whole reward. An empty value yields
+// no splits.
+func ParseRewardSplits(value string) ([]RewardSplit, error) {
+	splits := []RewardSplit{}
+	if strings.TrimSpace(value) == "" {
+		return splits, nil
+	}
+
+	entries := strings.Split(value, ",")
+	for _, entry := range entries {
+		address, percentage, found := strings.Cut(strings.TrimSpace(entry), ":")
+
+		split := RewardSplit{Address: address, Percentage: 100}
+		if found {
+			p, err := strconv.ParseFloat(percentage, 64)
+			if err != nil {
+				return nil, utils.GenericError{Msg: fmt.Sprintf("invalid reward percentage '%v'", percentage)}
+			}
+			split.Percentage = p
+		} else if len(entries) > 1 {
+			return nil, utils.GenericError{Msg: fmt.Sprintf("missing reward percentage of '%v'", address)}
+		}
+
+		splits = append(splits, split)
+	}
+
+	return splits, ValidateRewardSplits(splits)
+}
+
+// ValidateRewardSplits checks that the addresses are valid and distinct and
+// that the percentages are positive and add up to 100.
+func ValidateRewardSplits(splits []RewardSplit) error {
+	seen := make(map[string]bool)
+	total := 0.0
+
+	for _, split := range splits {
+		if !wallet.IsValidAddress(split.Address) {
+			return utils.GenericError{Msg: fmt.Sprintf("invalid reward address '%v'", split.Address)}
+		}
+
+		if seen[split.Address] {
+			return utils.GenericError{Msg: fmt.Sprintf("duplicate reward address '%v'", split.Address)}
+		}
+		seen[split.Address] = true
+
+		if split.Percentage <= 0 {
+			return utils.GenericError{Msg: fmt.Sprintf("reward percentage of '%v' should be positive", split.Address)}
+		}
+		total += split.Percentage
+	}
+
+	if len(splits) > 0 && math.Abs(total-100) > 1e-9 {
+		return utils.GenericError{Msg: fmt.Sprintf("reward percentages add up to %v instead of 100", total)}
+	}
+
+	return nil
+}
+
+// RewardTxs builds the coinbase transactions splitting the reward amount.
+func RewardTxs(splits []RewardSplit, amount float64, coinbaseSender string) []bc.Transaction {
+	return utils.Map(splits, func(split RewardSplit) bc.Transaction {
+		return bc.Transaction{
+			Body: bc.TransactionBody{
+				Sender:    coinbaseSender,
+				Recipient: split.Address,
+				Amount:    amount * split.Percentage / 100,
+			},
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package mining
+
+import (
+	"strings"
+	"testing"
+)
+
+var john = strings.Repeat("a1", 20)
+var jane = strings.Repeat("b2", 20)
+
+func TestParseRewardSplits(t *testing.T) {
+	splits, err := ParseRewardSplits(john + ":60, " + jane + ":40")
+	if err != nil {
+		t.Fatalf("Expected splits but got: %v", err)
+	}
+
+	txs := RewardTxs(splits, 2.0, "0")
+	if len(txs) != 2 || txs[0].Body.Recipient != john || txs[0].Body.Amount != 1.2 || txs[1].Body.Amount != 0.8 {
+		t.Errorf("Expected the reward to be split 60/40 but got %v", txs)
+	}
+
+	if splits, err := ParseRewardSplits(john); err != nil || len(splits) != 1 || splits[0].Percentage != 100 {
+		t.Errorf("Expected a single address to get the whole reward but got %v, %v", splits, err)
+	}
+
+	if splits, err := ParseRewardSplits(""); err != nil || len(splits) != 0 {
+		t.Errorf("Expected no splits but got %v, %v", splits, err)
+	}
+}
+
+func TestParseInvalidRewardSplits(t *testing.T) {
+	invalid := []string{
+		john + ":60," + jane + ":30",
+		john + ":100,invalid:0",
+		john + "," + jane,
+		john + ":50," + john + ":50",
+		john + ":abc",
+	}
+
+	for _, value := range invalid {
+		if _, err := ParseRewardSplits(value); err == nil {
+			t.Errorf("Expected '%v' to be rejected", value)
+		}
+	}
+}
@@ -10,6 +10,9 @@ type Node struct {
 	Schema string `json:"schema"`
 	IP     string `json:"ip"`
 	Port   string `json:"port"`
+	Pruned bool   `json:"pruned,omitempty"`
+	// Time is the sender's clock in milliseconds, reported when pinging.
+	Time int64 `json:"time,omitempty"`
 }
 
 func NewNode(name string, ip string, port string) Node {
@@ -24,6 +27,7 @@ func NewNode(name string, ip string, port string) Node {
 func (n *Node) Update(updated Node) {
 	n.IP = updated.IP
 	n.Port = updated.Port
+	n.Pruned = updated.Pruned
 }
 
 func (n Node) GetHost() string {
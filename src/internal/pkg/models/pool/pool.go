@@ -0,0 +1,122 @@
+package pool
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Protocol methods of the line-JSON pool protocol. A miner logs in with the
+// address its payouts are sent to, gets notified of jobs and submits the
+// nonces that meet the share target of a job.
+const (
+	LoginMethod  = "login"
+	SubmitMethod = "submit"
+	JobMethod    = "job"
+)
+
+// Message is a line of the pool protocol. Requests carry an Id, Method and
+// Params, responses the Id of the request along with a Result or an Error and
+// notifications a Method and Params only.
+type Message struct {
+	Id     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type LoginParams struct {
+	Address string `json:"address"`
+}
+
+type SubmitParams struct {
+	JobId string `json:"jobId"`
+	Nonce int64  `json:"nonce"`
+}
+
+// Job is the work unit sent to the miners. The nonce is written big endian at
+// NonceOffset of HeaderBytes. Hashes starting with ShareTarget are accepted
+// as shares and those also starting with Target, the one of the network's
+// Difficulty, solve the block.
+type Job struct {
+	Id          string `json:"id"`
+	HeaderBytes string `json:"headerBytes"`
+	NonceOffset int    `json:"nonceOffset"`
+	ShareTarget string `json:"shareTarget"`
+	Target      string `json:"target"`
+	Difficulty  int    `json:"difficulty"`
+}
+
+type SubmitResult struct {
+	Accepted    bool `json:"accepted"`
+	BlockFound  bool `json:"blockFound"`
+	RoundShares int  `json:"roundShares"`
+}
+
+// MinerStats counts the shares of a miner over the lifetime of the pool.
+type MinerStats struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+// Payout is the part of a block reward a miner gets.
+type Payout struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+}
+
+// Accounting keeps the shares of the current round, i.e. the shares since the
+// last block found, and the overall per miner statistics.
+type Accounting struct {
+	Round  map[string]int        `json:"round"`
+	Miners map[string]MinerStats `json:"miners"`
+	Blocks int                   `json:"blocks"`
+}
+
+func NewAccounting() *Accounting {
+	return &Accounting{Round: make(map[string]int), Miners: make(map[string]MinerStats)}
+}
+
+func (a *Accounting) AcceptShare(address string) {
+	a.Round[address]++
+
+	stats := a.Miners[address]
+	stats.Accepted++
+	a.Miners[address] = stats
+}
+
+func (a *Accounting) RejectShare(address string) {
+	stats := a.Miners[address]
+	stats.Rejected++
+	a.Miners[address] = stats
+}
+
+func (a *Accounting) RoundShares() int {
+	total := 0
+	for _, shares := range a.Round {
+		total += shares
+	}
+
+	return total
+}
+
+// CloseRound splits the reward proportionally to the shares of the current
+// round and starts a new one.
+func (a *Accounting) CloseRound(reward float64) []Payout {
+	total := a.RoundShares()
+
+	payouts := []Payout{}
+	for address, shares := range a.Round {
+		payouts = append(payouts, Payout{
+			Address: address,
+			Amount:  reward * float64(shares) / float64(total),
+		})
+	}
+
+	sort.Slice(payouts, func(i, j int) bool { return payouts[i].Address < payouts[j].Address })
+
+	a.Round = make(map[string]int)
+	a.Blocks++
+
+	return payouts
+}
@@ -0,0 +1,45 @@
+package pool
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCloseRoundSplitsProportionally(t *testing.T) {
+	accounting := NewAccounting()
+	for i := 0; i < 3; i++ {
+		accounting.AcceptShare("John")
+	}
+	accounting.AcceptShare("Jane")
+	accounting.RejectShare("Jane")
+
+	if shares := accounting.RoundShares(); shares != 4 {
+		t.Errorf("Expected 4 round shares but got %v", shares)
+	}
+
+	payouts := accounting.CloseRound(2.0)
+
+	expected := []Payout{{Address: "Jane", Amount: 0.5}, {Address: "John", Amount: 1.5}}
+	if len(payouts) != len(expected) {
+		t.Fatalf("Expected %v payouts but got %v", len(expected), len(payouts))
+	}
+	for i, payout := range payouts {
+		if payout.Address != expected[i].Address || math.Abs(payout.Amount-expected[i].Amount) > 1e-9 {
+			t.Errorf("Expected payout %v but got %v", expected[i], payout)
+		}
+	}
+
+	if shares := accounting.RoundShares(); shares != 0 {
+		t.Errorf("Expected a new round but got %v shares", shares)
+	}
+
+	if stats := accounting.Miners["Jane"]; stats.Accepted != 1 || stats.Rejected != 1 {
+		t.Errorf("Expected Jane's stats to be kept but got %v", stats)
+	}
+}
+
+func TestCloseEmptyRound(t *testing.T) {
+	if payouts := NewAccounting().CloseRound(1.0); len(payouts) != 0 {
+		t.Errorf("Expected no payouts but got %v", payouts)
+	}
+}
@@ -0,0 +1,124 @@
+package submission
+
+import (
+	"encoding/json"
+	"math"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Confirmed Status = "confirmed"
+	Dropped   Status = "dropped"
+	Rejected  Status = "rejected"
+)
+
+// Submission keeps track of a transaction that was submitted to this node
+// until it gets confirmed or expires.
+type Submission struct {
+	Tx              bc.Transaction `json:"tx"`
+	Status          Status         `json:"status"`
+	Attempts        int            `json:"attempts"`
+	SubmittedAt     int64          `json:"submittedAt"`
+	LastBroadcastAt int64          `json:"lastBroadcastAt"`
+	NextBroadcastAt int64          `json:"nextBroadcastAt"`
+	Reason          string         `json:"reason,omitempty"`
+}
+
+func NewSubmission(tx bc.Transaction, now int64) Submission {
+	return Submission{
+		Tx:          tx,
+		Status:      Pending,
+		SubmittedAt: now,
+	}
+}
+
+func NewRejectedSubmission(tx bc.Transaction, now int64, reason string) Submission {
+	return Submission{
+		Tx:          tx,
+		Status:      Rejected,
+		SubmittedAt: now,
+		Reason:      reason,
+	}
+}
+
+func (s Submission) Id() string {
+	return s.Tx.Id
+}
+
+func (s Submission) IsPending() bool {
+	return s.Status == Pending
+}
+
+func (s Submission) IsDue(now int64) bool {
+	return s.IsPending() && now >= s.NextBroadcastAt
+}
+
+func (s Submission) IsExpired(now int64, expiry int64) bool {
+	return now-s.SubmittedAt > expiry
+}
+
+// Broadcasted records a broadcast attempt and schedules the next one after an
+// exponentially growing interval, capped by maxInterval.
+func (s *Submission) Broadcasted(now int64, interval int64, maxInterval int64) {
+	s.Attempts++
+	s.LastBroadcastAt = now
+
+	backoff := float64(interval) * math.Pow(2, float64(s.Attempts-1))
+	s.NextBroadcastAt = now + int64(math.Min(backoff, float64(maxInterval)))
+}
+
+// Receipt returns the receipt of the submission when it has reached a status
+// which cannot be derived from the blockchain itself.
+func (s Submission) Receipt() (bc.Receipt, bool) {
+	switch s.Status {
+	case Rejected:
+		return bc.Receipt{TxId: s.Id(), Status: bc.TxRejected, Reason: s.Reason}, true
+	case Dropped:
+		return bc.Receipt{TxId: s.Id(), Status: bc.TxDropped, Reason: "transaction expired"}, true
+	default:
+		return bc.Receipt{}, false
+	}
+}
+
+func Unmarshal(data []byte) (submission Submission, err error) {
+	err = json.Unmarshal(data, &submission)
+	return
+}
+
+func UnmarshalMany(data []byte) (submissions []Submission, err error) {
+	err = json.Unmarshal(data, &submissions)
+	return
+}
+
+func AddSubmission(submissions []Submission, submission Submission) ([]Submission, error) {
+	if Find(submissions, submission.Id()) != -1 {
+		return nil, utils.GenericError{Msg: "submission already exists"}
+	}
+
+	return append(submissions, submission), nil
+}
+
+func UpdateSubmission(submissions []Submission, submission Submission) ([]Submission, error) {
+	index := Find(submissions, submission.Id())
+	if index == -1 {
+		return nil, utils.GenericError{Msg: "submission not found"}
+	}
+
+	submissions[index] = submission
+
+	return submissions, nil
+}
+
+func Find(submissions []Submission, id string) int {
+	for i, s := range submissions {
+		if s.Id() == id {
+			return i
+		}
+	}
+	return -1
+}
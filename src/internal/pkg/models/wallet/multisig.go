@@ -0,0 +1,136 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const maxMultisigKeys = 16
+
+// Multisig is an M-of-N account: its address derives from the public keys of
+// its N owners and the Threshold of M owners whose signatures spending from
+// it needs.
+type Multisig struct {
+	Address    []byte   `json:"address"`
+	Threshold  int      `json:"threshold"`
+	PublicKeys [][]byte `json:"publicKeys"`
+}
+
+func UnmarshalMultisigs(data []byte) (multisigs []Multisig, err error) {
+	err = json.Unmarshal(data, &multisigs)
+	return
+}
+
+func NewMultisig(threshold int, publicKeys [][]byte) (*Multisig, error) {
+	multisig := Multisig{
+		Address:    MultisigAddress(threshold, publicKeys),
+		Threshold:  threshold,
+		PublicKeys: sortedKeys(publicKeys),
+	}
+
+	if err := multisig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &multisig, nil
+}
+
+// MultisigAddress derives the address of the multisig from its threshold and
+// public keys, regardless of their order.
+func MultisigAddress(threshold int, publicKeys [][]byte) []byte {
+	data := []byte{byte(threshold)}
+	for _, publicKey := range sortedKeys(publicKeys) {
+		data = append(data, publicKey...)
+	}
+
+	hash := crypto.HashData(data)
+
+	return hash[len(hash)-addressSize:]
+}
+
+func sortedKeys(publicKeys [][]byte) [][]byte {
+	sorted := append([][]byte{}, publicKeys...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	return sorted
+}
+
+// Validate checks that the multisig is well formed and that its address
+// derives from its threshold and public keys.
+func (m Multisig) Validate() error {
+	if len(m.PublicKeys) == 0 || len(m.PublicKeys) > maxMultisigKeys {
+		return utils.GenericError{Msg: "a multisig needs between 1 and 16 public keys"}
+	}
+
+	if m.Threshold < 1 || m.Threshold > len(m.PublicKeys) {
+		return utils.GenericError{Msg: "the multisig threshold has to be between 1 and the number of public keys"}
+	}
+
+	owners := map[string]bool{}
+	for _, publicKey := range m.PublicKeys {
+		owner, err := ownerAddress(publicKey)
+		if err != nil {
+			return err
+		}
+		if owners[owner] {
+			return utils.GenericError{Msg: "duplicate multisig public key"}
+		}
+		owners[owner] = true
+	}
+
+	if !bytes.Equal(m.Address, MultisigAddress(m.Threshold, m.PublicKeys)) {
+		return utils.GenericError{Msg: "multisig address does not match its public keys"}
+	}
+
+	return nil
+}
+
+func ownerAddress(publicKey []byte) (string, error) {
+	publicKeyECDSA, err := crypto.UnmarshalPublicKey(publicKey)
+	if err != nil {
+		return "", utils.GenericError{Msg: "invalid multisig public key", Extra: err}
+	}
+
+	return hex.EncodeToString(crypto.AddressFromPublicKey(publicKeyECDSA)), nil
+}
+
+func (m Multisig) AddressString() string {
+	return hex.EncodeToString(m.Address)
+}
+
+// Signer returns the address of the owner who signed the data with the
+// signature.
+func (m Multisig) Signer(data []byte, signature string) (string, error) {
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil || len(signatureBytes) == 0 {
+		return "", utils.GenericError{Msg: "failed to decode signature"}
+	}
+
+	for _, publicKey := range m.PublicKeys {
+		if crypto.VerifySignature(data, publicKey, signatureBytes) {
+			return ownerAddress(publicKey)
+		}
+	}
+
+	return "", utils.GenericError{Msg: "signature does not belong to any of the multisig owners"}
+}
+
+// CountSigners returns the number of distinct owners who signed the data
+// with the signatures.
+func (m Multisig) CountSigners(data []byte, signatures []string) (int, error) {
+	signers := map[string]bool{}
+	for _, signature := range signatures {
+		signer, err := m.Signer(data, signature)
+		if err != nil {
+			return 0, err
+		}
+		signers[signer] = true
+	}
+
+	return len(signers), nil
+}
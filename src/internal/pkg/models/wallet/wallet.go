@@ -7,6 +7,8 @@ import (
 	"github.com/antavelos/blockchain/src/pkg/crypto"
 )
 
+const addressSize = 20
+
 type Wallet struct {
 	Address    []byte `json:"address"`
 	PrivateKey []byte `json:"privateKey"`
@@ -54,6 +56,14 @@ func (w Wallet) AddressString() string {
 	return hex.EncodeToString(w.Address)
 }
 
+// IsValidAddress checks that the address is the hex form of a 20 bytes
+// address.
+func IsValidAddress(address string) bool {
+	addressBytes, err := hex.DecodeString(address)
+
+	return err == nil && len(addressBytes) == addressSize
+}
+
 func (w Wallet) VerifySignature(data []byte, signature []byte) bool {
 	return crypto.VerifySignature(data, w.PublicKey, signature)
 }
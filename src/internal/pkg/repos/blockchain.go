@@ -1,21 +1,49 @@
 package repos
 
 import (
-	"encoding/json"
 	"time"
 
 	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
 	database "github.com/antavelos/blockchain/src/pkg/db"
+	"github.com/antavelos/blockchain/src/pkg/nettime"
 	"github.com/antavelos/blockchain/src/pkg/utils"
 	"github.com/google/uuid"
 )
 
+// BlockchainRepo stores the node's blockchain. When Consensus is set, added
+// blocks and transactions are verified against its rules. Added blocks and
+// reorgs are refused when they violate the Finality, and added blocks when
+// they exceed the Limits. When Clock is set, added blocks' timestamps are
+// checked against the Timestamps rules.
 type BlockchainRepo struct {
-	db *database.DB
+	Consensus  bc.Consensus
+	Finality   bc.Finality
+	Limits     bc.ConsensusLimits
+	Timestamps bc.TimestampRules
+	Clock      *nettime.Clock
+
+	db        *database.DB
+	indexRepo *IndexRepo
+}
+
+func NewBlockchainRepo(db *database.DB, indexRepo *IndexRepo) *BlockchainRepo {
+	return &BlockchainRepo{db: db, indexRepo: indexRepo}
 }
 
-func NewBlockchainRepo(db *database.DB) *BlockchainRepo {
-	return &BlockchainRepo{db: db}
+// syncIndex updates the indexes after the blocks of the blockchain changed.
+// It is called while holding the blockchain's lock so that the indexes follow
+// the blockchain updates in order. The update of the blockchain is aborted
+// when the indexes cannot follow it.
+func (r *BlockchainRepo) syncIndex(oldBlocks []bc.Block, newBlocks []bc.Block) error {
+	if r.indexRepo == nil {
+		return nil
+	}
+
+	if err := r.indexRepo.Sync(oldBlocks, newBlocks); err != nil {
+		return utils.GenericError{Msg: "failed to update indexes", Extra: err}
+	}
+
+	return nil
 }
 
 func (r *BlockchainRepo) GetBlockchain() (blockchain *bc.Blockchain, err error) {
@@ -24,23 +52,49 @@ func (r *BlockchainRepo) GetBlockchain() (blockchain *bc.Blockchain, err error)
 		return &bc.Blockchain{}, err
 	}
 
-	err = json.Unmarshal(data, &blockchain)
+	unmarshalled, err := bc.UnmarshalBlockchain(data)
+	if err != nil {
+		return &bc.Blockchain{}, err
+	}
 
-	return
+	return &unmarshalled, nil
 }
 
 func (r *BlockchainRepo) UpdateBlockchain(other *bc.Blockchain) error {
 	return r.db.WithLock(func(data []byte) (any, error) {
 		blockchain, _ := bc.UnmarshalBlockchain(data)
+		oldBlocks := blockchain.Blocks
+
+		if err := r.Finality.CheckReorg(&blockchain, other); err != nil {
+			return nil, err
+		}
 
 		blockchain.Update(other)
+		if err := r.syncIndex(oldBlocks, other.Blocks); err != nil {
+			return nil, err
+		}
 
 		return blockchain, nil
 	})
 }
 
 func (r *BlockchainRepo) ReplaceBlockchain(other bc.Blockchain) error {
-	return r.db.Save(other)
+	return r.db.WithLock(func(data []byte) (any, error) {
+		var oldBlocks []bc.Block
+		if current, err := bc.UnmarshalBlockchain(data); err == nil {
+			oldBlocks = current.Blocks
+
+			if err := r.Finality.CheckReorg(&current, &other); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := r.syncIndex(oldBlocks, other.Blocks); err != nil {
+			return nil, err
+		}
+
+		return other, nil
+	})
 }
 
 func (r *BlockchainRepo) AddTx(tx bc.Transaction) (bc.Transaction, error) {
@@ -56,7 +110,7 @@ func (r *BlockchainRepo) AddTx(tx bc.Transaction) (bc.Transaction, error) {
 	err := r.db.WithLock(func(data []byte) (any, error) {
 		blockchain, _ := bc.UnmarshalBlockchain(data)
 
-		_, err := blockchain.AddTx(tx)
+		_, err := blockchain.AddTx(r.Consensus, tx)
 		if err != nil {
 			return nil, err
 		}
@@ -67,26 +121,163 @@ func (r *BlockchainRepo) AddTx(tx bc.Transaction) (bc.Transaction, error) {
 	return tx, err
 }
 
-func (r *BlockchainRepo) AddBlock(block bc.Block) error {
+// HasTx reports whether the transaction is pending or confirmed, looking the
+// confirmed ones up in the index when there is one.
+func (r *BlockchainRepo) HasTx(tx bc.Transaction) bool {
+	blockchain, err := r.GetBlockchain()
+	if err != nil {
+		return false
+	}
+
+	if r.indexRepo == nil {
+		return blockchain.HasTx(tx)
+	}
+
+	if _, ok := blockchain.GetPendingTx(tx.Id); ok {
+		return true
+	}
+
+	index, err := r.indexRepo.GetIndex()
+	if err != nil {
+		return blockchain.HasTx(tx)
+	}
+
+	return index.HasTx(tx.Id)
+}
+
+func (r *BlockchainRepo) GetTxPoolIds() ([]string, error) {
+	blockchain, err := r.GetBlockchain()
+	if err != nil {
+		return nil, err
+	}
+
+	return blockchain.TxPoolIds(), nil
+}
+
+func (r *BlockchainRepo) GetPoolTxs(ids []string) ([]bc.Transaction, error) {
+	blockchain, err := r.GetBlockchain()
+	if err != nil {
+		return nil, err
+	}
+
+	return blockchain.GetPoolTxs(ids), nil
+}
+
+func (r *BlockchainRepo) GetReceipt(txId string) (bc.Receipt, bool) {
+	blockchain, err := r.GetBlockchain()
+	if err != nil {
+		return bc.Receipt{}, false
+	}
+
+	return blockchain.GetReceipt(txId)
+}
+
+func (r *BlockchainRepo) RemovePendingTx(tx bc.Transaction) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		blockchain, _ := bc.UnmarshalBlockchain(data)
+
+		blockchain.RemovePendingTx(tx)
+
+		return blockchain, nil
+	})
+}
+
+// DropInvalidTxs evicts the pending transactions that no longer verify and
+// returns them.
+func (r *BlockchainRepo) DropInvalidTxs() ([]bc.Transaction, error) {
+	var dropped []bc.Transaction
+
 	err := r.db.WithLock(func(data []byte) (any, error) {
 		blockchain, _ := bc.UnmarshalBlockchain(data)
 
+		dropped = blockchain.DropInvalidTxs(r.Consensus)
+
+		return blockchain, nil
+	})
+
+	return dropped, err
+}
+
+func (r *BlockchainRepo) AddBlock(block bc.Block) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		blockchain, _ := bc.UnmarshalBlockchain(data)
+		oldBlocks := append([]bc.Block{}, blockchain.Blocks...)
+
+		if r.Consensus != nil {
+			if err := blockchain.VerifyBlock(r.Consensus, block); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := r.Finality.CheckBlock(block); err != nil {
+			return nil, err
+		}
+
+		if err := r.Limits.CheckBlock(block); err != nil {
+			return nil, err
+		}
+
+		if r.Clock != nil {
+			if err := r.Timestamps.Check(&blockchain, block, r.Clock.Now()); err != nil {
+				return nil, err
+			}
+		}
+
 		err := blockchain.AddBlock(block)
 		if err != nil {
 			return nil, err
 		}
+		if err := r.syncIndex(oldBlocks, blockchain.Blocks); err != nil {
+			return nil, err
+		}
 
 		return blockchain, nil
 	})
+}
+
+// Prune keeps the transactions of the last keep blocks only. It reports
+// whether any block got pruned.
+func (r *BlockchainRepo) Prune(keep int64) (bool, error) {
+	pruned := false
+
+	err := r.db.WithLock(func(data []byte) (any, error) {
+		blockchain, _ := bc.UnmarshalBlockchain(data)
+
+		pruned = blockchain.Prune(keep)
+
+		return blockchain, nil
+	})
+
+	return pruned, err
+}
+
+// Reindex rebuilds the indexes from the blocks of the local blockchain.
+func (r *BlockchainRepo) Reindex() error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		blockchain, err := bc.UnmarshalBlockchain(data)
+		if err != nil {
+			return nil, utils.GenericError{Msg: "blockchain currently not available", Extra: err}
+		}
 
-	return err
+		if err := r.indexRepo.Rebuild(blockchain.Blocks); err != nil {
+			return nil, err
+		}
+
+		return blockchain, nil
+	})
 }
 
 func (r *BlockchainRepo) CreateBlockchain() (*bc.Blockchain, error) {
 
 	blockchain := bc.NewBlockchain()
 
-	err := r.db.Save(*blockchain)
+	err := r.db.WithLock(func(data []byte) (any, error) {
+		if err := r.syncIndex(nil, blockchain.Blocks); err != nil {
+			return nil, err
+		}
+
+		return *blockchain, nil
+	})
 	if err != nil {
 		return nil, utils.GenericError{Msg: "failed to save new wallet"}
 	}
@@ -0,0 +1,67 @@
+package repos
+
+import (
+	"path/filepath"
+	"testing"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+)
+
+func newTestBlockchainRepo(t *testing.T, indexFilename string) *BlockchainRepo {
+	repo := NewBlockchainRepo(
+		database.NewDB(filepath.Join(t.TempDir(), "blockchain.json")),
+		NewIndexRepo(database.NewDB(indexFilename)),
+	)
+
+	if _, err := repo.CreateBlockchain(); err != nil {
+		t.Fatalf("Expected blockchain but got: %v", err)
+	}
+
+	return repo
+}
+
+func coinbaseTx(id string) bc.Transaction {
+	return bc.Transaction{Id: id, Body: bc.TransactionBody{Sender: "0", Recipient: "John", Amount: 1}}
+}
+
+func TestBlockchainRepoHasTxLooksUpTheIndex(t *testing.T) {
+	repo := newTestBlockchainRepo(t, filepath.Join(t.TempDir(), "index.json"))
+
+	blockchain, _ := repo.GetBlockchain()
+	blockchain.TxPool = []bc.Transaction{coinbaseTx("confirmed")}
+	block, _ := blockchain.NewBlock(1)
+	if err := repo.AddBlock(block); err != nil {
+		t.Fatalf("Expected block to be added but got: %v", err)
+	}
+
+	if _, err := repo.AddTx(coinbaseTx("pending")); err != nil {
+		t.Fatalf("Expected transaction to be added but got: %v", err)
+	}
+
+	for id, expected := range map[string]bool{"confirmed": true, "pending": true, "unknown": false} {
+		if found := repo.HasTx(coinbaseTx(id)); found != expected {
+			t.Errorf("Expected %v to be found %v but got %v", id, expected, found)
+		}
+	}
+}
+
+func TestBlockchainRepoAbortsUpdateWhenIndexFails(t *testing.T) {
+	indexDir := t.TempDir()
+	repo := newTestBlockchainRepo(t, filepath.Join(indexDir, "index.json"))
+
+	blockchain, _ := repo.GetBlockchain()
+	blockchain.TxPool = []bc.Transaction{coinbaseTx("tx")}
+	block, _ := blockchain.NewBlock(1)
+
+	// the index cannot be read from a directory
+	repo.indexRepo = NewIndexRepo(database.NewDB(indexDir))
+
+	if err := repo.AddBlock(block); err == nil {
+		t.Errorf("Expected the block to be refused when the index fails but got %v", err)
+	}
+
+	if blockchain, _ := repo.GetBlockchain(); len(blockchain.Blocks) != 1 {
+		t.Errorf("Expected the blockchain to be left unchanged but got %v blocks", len(blockchain.Blocks))
+	}
+}
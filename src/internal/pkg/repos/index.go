@@ -0,0 +1,39 @@
+package repos
+
+import (
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	idx "github.com/antavelos/blockchain/src/internal/pkg/models/index"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+)
+
+type IndexRepo struct {
+	db *database.DB
+}
+
+func NewIndexRepo(db *database.DB) *IndexRepo {
+	return &IndexRepo{db: db}
+}
+
+func (r *IndexRepo) GetIndex() (*idx.Index, error) {
+	data, err := r.db.Load()
+	if err != nil {
+		return idx.NewIndex(), err
+	}
+
+	return idx.Unmarshal(data)
+}
+
+func (r *IndexRepo) Sync(oldBlocks []bc.Block, newBlocks []bc.Block) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		index, err := idx.Unmarshal(data)
+		if err != nil {
+			return idx.Rebuild(newBlocks), nil
+		}
+
+		return index.Sync(oldBlocks, newBlocks), nil
+	})
+}
+
+func (r *IndexRepo) Rebuild(blocks []bc.Block) error {
+	return r.db.Save(idx.Rebuild(blocks))
+}
@@ -0,0 +1,61 @@
+package repos
+
+import (
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	"github.com/antavelos/blockchain/src/internal/pkg/models/light"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+)
+
+type LightRepo struct {
+	db *database.DB
+}
+
+func NewLightRepo(db *database.DB) *LightRepo {
+	return &LightRepo{db: db}
+}
+
+func (r *LightRepo) GetChain() (light.Chain, error) {
+	data, err := r.db.Load()
+	if err != nil {
+		return light.Chain{}, err
+	}
+
+	return light.Unmarshal(data)
+}
+
+func (r *LightRepo) update(process func(chain *light.Chain) error) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		chain, _ := light.Unmarshal(data)
+
+		if err := process(&chain); err != nil {
+			return nil, err
+		}
+
+		return chain, nil
+	})
+}
+
+func (r *LightRepo) ExtendHeaders(headers []bc.BlockHeader, consensus bc.Consensus, genesisHash string) error {
+	return r.update(func(chain *light.Chain) error {
+		return chain.Extend(headers, consensus, genesisHash)
+	})
+}
+
+func (r *LightRepo) ReplaceHeaders(headers []bc.BlockHeader, consensus bc.Consensus, genesisHash string) error {
+	return r.update(func(chain *light.Chain) error {
+		return chain.Replace(headers, consensus, genesisHash)
+	})
+}
+
+func (r *LightRepo) Watch(address string) error {
+	return r.update(func(chain *light.Chain) error {
+		chain.Watch(address)
+		return nil
+	})
+}
+
+func (r *LightRepo) AddProvenTx(proof bc.InclusionProof) error {
+	return r.update(func(chain *light.Chain) error {
+		return chain.AddProvenTx(proof)
+	})
+}
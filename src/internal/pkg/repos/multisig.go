@@ -0,0 +1,132 @@
+package repos
+
+import (
+	"encoding/json"
+	"strings"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	w "github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var ErrMultisigNotFound = utils.GenericError{Msg: "multisig not found"}
+var ErrProposalNotFound = utils.GenericError{Msg: "proposal not found"}
+
+// MultisigRepo stores the multisigs and the transactions spending from them
+// while their owners' signatures are being collected.
+type MultisigRepo struct {
+	db          *database.DB
+	proposalsDB *database.DB
+}
+
+func NewMultisigRepo(db *database.DB, proposalsDB *database.DB) *MultisigRepo {
+	return &MultisigRepo{db: db, proposalsDB: proposalsDB}
+}
+
+func (r *MultisigRepo) CreateMultisig(threshold int, publicKeys [][]byte) (*w.Multisig, error) {
+	multisig, err := w.NewMultisig(threshold, publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.WithLock(func(data []byte) (any, error) {
+		multisigs, _ := w.UnmarshalMultisigs(data)
+
+		for _, other := range multisigs {
+			if other.AddressString() == multisig.AddressString() {
+				return multisigs, nil
+			}
+		}
+
+		return append(multisigs, *multisig), nil
+	})
+
+	return multisig, err
+}
+
+func (r *MultisigRepo) GetMultisig(address string) (w.Multisig, error) {
+	data, err := r.db.Load()
+	if err != nil {
+		return w.Multisig{}, err
+	}
+
+	multisigs, _ := w.UnmarshalMultisigs(data)
+	for _, multisig := range multisigs {
+		if strings.EqualFold(multisig.AddressString(), address) {
+			return multisig, nil
+		}
+	}
+
+	return w.Multisig{}, ErrMultisigNotFound
+}
+
+// Propose stores a transaction spending from the multisig of the given
+// address until enough of its owners sign it. The nonce is the multisig's
+// next one on the network.
+func (r *MultisigRepo) Propose(address string, recipient string, amount float64, fee float64, nonce uint64) (bc.Transaction, error) {
+	multisig, err := r.GetMultisig(address)
+	if err != nil {
+		return bc.Transaction{}, err
+	}
+
+	tx := bc.NewMultisigTransaction(multisig, recipient, amount, fee, nonce)
+	tx.Id = uuid.NewString()
+
+	err = r.proposalsDB.WithLock(func(data []byte) (any, error) {
+		proposals, _ := unmarshalProposals(data)
+
+		return append(proposals, tx), nil
+	})
+
+	return tx, err
+}
+
+func (r *MultisigRepo) GetProposal(id string) (bc.Transaction, error) {
+	data, err := r.proposalsDB.Load()
+	if err != nil {
+		return bc.Transaction{}, err
+	}
+
+	proposals, _ := unmarshalProposals(data)
+	for _, proposal := range proposals {
+		if proposal.Id == id {
+			return proposal, nil
+		}
+	}
+
+	return bc.Transaction{}, ErrProposalNotFound
+}
+
+// AddSignature adds the signature of one of the multisig's owners to the
+// proposed transaction.
+func (r *MultisigRepo) AddSignature(id string, signature string) (bc.Transaction, error) {
+	var signed bc.Transaction
+
+	err := r.proposalsDB.WithLock(func(data []byte) (any, error) {
+		proposals, _ := unmarshalProposals(data)
+
+		for i := range proposals {
+			if proposals[i].Id != id {
+				continue
+			}
+
+			if err := proposals[i].AddSignature(signature); err != nil {
+				return nil, err
+			}
+			signed = proposals[i]
+
+			return proposals, nil
+		}
+
+		return nil, ErrProposalNotFound
+	})
+
+	return signed, err
+}
+
+func unmarshalProposals(data []byte) (proposals []bc.Transaction, err error) {
+	err = json.Unmarshal(data, &proposals)
+	return
+}
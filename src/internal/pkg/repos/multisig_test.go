@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	w "github.com/antavelos/blockchain/src/internal/pkg/models/wallet"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+)
+
+func newTestMultisig(t *testing.T) (*MultisigRepo, *w.Multisig, []*w.Wallet) {
+	dir := t.TempDir()
+	repo := NewMultisigRepo(
+		database.NewDB(filepath.Join(dir, "multisigs.json")),
+		database.NewDB(filepath.Join(dir, "proposals.json")),
+	)
+
+	owners := []*w.Wallet{}
+	publicKeys := [][]byte{}
+	for i := 0; i < 3; i++ {
+		owner, err := w.NewWallet()
+		if err != nil {
+			t.Fatalf("Expected wallet but got: %v", err)
+		}
+		owners = append(owners, owner)
+		publicKeys = append(publicKeys, owner.PublicKey)
+	}
+
+	multisig, err := repo.CreateMultisig(2, publicKeys)
+	if err != nil {
+		t.Fatalf("Expected multisig but got: %v", err)
+	}
+
+	return repo, multisig, owners
+}
+
+func signProposal(t *testing.T, repo *MultisigRepo, id string, owner *w.Wallet) string {
+	proposal, err := repo.GetProposal(id)
+	if err != nil {
+		t.Fatalf("Expected proposal but got: %v", err)
+	}
+
+	txBodyBytes, _ := json.Marshal(proposal.Body)
+	signature, err := owner.Sign(txBodyBytes)
+	if err != nil {
+		t.Fatalf("Expected signature but got: %v", err)
+	}
+
+	return signature
+}
+
+func TestMultisigRepoCollectsSignatures(t *testing.T) {
+	repo, multisig, owners := newTestMultisig(t)
+	recipient, _ := w.NewWallet()
+
+	proposal, err := repo.Propose(multisig.AddressString(), recipient.AddressString(), 5, 0, 0)
+	if err != nil {
+		t.Fatalf("Expected proposal but got: %v", err)
+	}
+
+	if stored, err := repo.GetProposal(proposal.Id); err != nil || stored.Body.Amount != 5 {
+		t.Errorf("Expected the proposal to be stored but got %v, %v", stored, err)
+	}
+
+	first := signProposal(t, repo, proposal.Id, owners[0])
+	if _, err := repo.AddSignature(proposal.Id, first); err != nil {
+		t.Fatalf("Expected the first owner to sign but got %v", err)
+	}
+
+	if _, err := repo.AddSignature(proposal.Id, first); err == nil {
+		t.Errorf("Expected a second signature of the same owner to be refused but got %v", err)
+	}
+
+	second := signProposal(t, repo, proposal.Id, owners[1])
+	signed, err := repo.AddSignature(proposal.Id, second)
+	if err != nil {
+		t.Fatalf("Expected the second owner to sign but got %v", err)
+	}
+	if !signed.IsFullySigned() {
+		t.Errorf("Expected the proposal to be fully signed but got %v signatures", len(signed.Signatures))
+	}
+
+	third := signProposal(t, repo, proposal.Id, owners[2])
+	if _, err := repo.AddSignature(proposal.Id, third); err == nil {
+		t.Errorf("Expected a signature past the threshold to be refused but got %v", err)
+	}
+
+	stored, err := repo.GetProposal(proposal.Id)
+	if err != nil || len(stored.Signatures) != 2 {
+		t.Errorf("Expected 2 stored signatures but got %v, %v", len(stored.Signatures), err)
+	}
+	if err := stored.Validate(); err != nil {
+		t.Errorf("Expected the stored proposal to be valid but got %v", err)
+	}
+}
+
+func TestMultisigRepoUnknownProposal(t *testing.T) {
+	repo, _, owners := newTestMultisig(t)
+
+	if _, err := repo.GetProposal("unknown"); err != ErrProposalNotFound {
+		t.Errorf("Expected ErrProposalNotFound but got %v", err)
+	}
+
+	if _, err := repo.AddSignature("unknown", "signature"); err != ErrProposalNotFound {
+		t.Errorf("Expected ErrProposalNotFound but got %v", err)
+	}
+
+	if _, err := repo.Propose(owners[0].AddressString(), owners[1].AddressString(), 5, 0, 0); err != ErrMultisigNotFound {
+		t.Errorf("Expected ErrMultisigNotFound but got %v", err)
+	}
+}
@@ -6,18 +6,32 @@ type DBFilenames struct {
 	BlockchainFilename string
 	NodeFilename       string
 	WalletFilename     string
+	SubmissionFilename string
+	IndexFilename      string
+	LightFilename      string
+	SnapshotsDir       string
 }
 
 type Repos struct {
 	BlockchainRepo *BlockchainRepo
 	NodeRepo       *NodeRepo
 	WalletRepo     *WalletRepo
+	SubmissionRepo *SubmissionRepo
+	IndexRepo      *IndexRepo
+	LightRepo      *LightRepo
+	SnapshotRepo   *SnapshotRepo
 }
 
 func InitRepos(filenames DBFilenames) *Repos {
+	indexRepo := NewIndexRepo(db.NewDB(filenames.IndexFilename))
+
 	return &Repos{
-		BlockchainRepo: NewBlockchainRepo(db.NewDB(filenames.BlockchainFilename)),
+		BlockchainRepo: NewBlockchainRepo(db.NewDB(filenames.BlockchainFilename), indexRepo),
 		NodeRepo:       NewNodeRepo(db.NewDB(filenames.NodeFilename)),
 		WalletRepo:     NewWalletRepo(db.NewDB(filenames.WalletFilename)),
+		SubmissionRepo: NewSubmissionRepo(db.NewDB(filenames.SubmissionFilename)),
+		IndexRepo:      indexRepo,
+		LightRepo:      NewLightRepo(db.NewDB(filenames.LightFilename)),
+		SnapshotRepo:   NewSnapshotRepo(filenames.SnapshotsDir),
 	}
 }
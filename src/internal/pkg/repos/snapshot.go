@@ -0,0 +1,135 @@
+package repos
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+const snapshotFilePrefix = "snapshot_"
+const snapshotFileSuffix = ".json"
+
+var ErrSnapshotNotFound = utils.GenericError{Msg: "snapshot not found"}
+
+// SnapshotRepo keeps every snapshot in its own file under a directory.
+type SnapshotRepo struct {
+	dir string
+}
+
+func NewSnapshotRepo(dir string) *SnapshotRepo {
+	return &SnapshotRepo{dir: dir}
+}
+
+func (r *SnapshotRepo) db(height int64) *database.DB {
+	filename := fmt.Sprintf("%v%v%v", snapshotFilePrefix, height, snapshotFileSuffix)
+	return database.NewDB(filepath.Join(r.dir, filename))
+}
+
+func (r *SnapshotRepo) heights() ([]int64, error) {
+	entries, err := os.ReadDir(r.dir)
+	if os.IsNotExist(err) {
+		return []int64{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	heights := []int64{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, snapshotFilePrefix) || !strings.HasSuffix(name, snapshotFileSuffix) {
+			continue
+		}
+
+		height, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, snapshotFilePrefix), snapshotFileSuffix), 10, 64)
+		if err != nil || height <= 0 {
+			continue
+		}
+
+		// empty files are not snapshots
+		if info, err := entry.Info(); err != nil || info.Size() == 0 {
+			continue
+		}
+
+		heights = append(heights, height)
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	return heights, nil
+}
+
+// GetSnapshot reads the snapshot of the height without creating its file,
+// unlike loading it through the db.
+func (r *SnapshotRepo) GetSnapshot(height int64) (bc.Snapshot, error) {
+	if height <= 0 {
+		return bc.Snapshot{}, ErrSnapshotNotFound
+	}
+
+	data, err := os.ReadFile(r.db(height).Filename)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return bc.Snapshot{}, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return bc.Snapshot{}, err
+	}
+
+	return bc.UnmarshalSnapshot(data)
+}
+
+func (r *SnapshotRepo) GetSnapshots() ([]bc.SnapshotInfo, error) {
+	heights, err := r.heights()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []bc.SnapshotInfo{}
+	for _, height := range heights {
+		snapshot, err := r.GetSnapshot(height)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, snapshot.SnapshotInfo)
+	}
+
+	return infos, nil
+}
+
+func (r *SnapshotRepo) LatestHeight() int64 {
+	heights, err := r.heights()
+	if err != nil || len(heights) == 0 {
+		return 0
+	}
+
+	return heights[len(heights)-1]
+}
+
+// AddSnapshot saves the snapshot and removes the oldest ones beyond keep.
+func (r *SnapshotRepo) AddSnapshot(snapshot bc.Snapshot, keep int) error {
+	if err := os.MkdirAll(r.dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := r.db(snapshot.Height).Save(snapshot); err != nil {
+		return err
+	}
+
+	heights, err := r.heights()
+	if err != nil {
+		return err
+	}
+
+	for len(heights) > keep {
+		os.Remove(r.db(heights[0]).Filename)
+		heights = heights[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repos
+
+import (
+	"os"
+	"testing"
+
+	bc "github.com/antavelos/blockchain/src/internal/pkg/models/blockchain"
+)
+
+func TestGetSnapshotDoesNotCreateMissingFiles(t *testing.T) {
+	repo := NewSnapshotRepo(t.TempDir())
+
+	if err := repo.AddSnapshot(bc.Snapshot{SnapshotInfo: bc.SnapshotInfo{Height: 5}, State: bc.NewState()}, 3); err != nil {
+		t.Fatalf("Failed to add snapshot: %v", err)
+	}
+
+	for _, height := range []int64{-1, 0, 1000} {
+		if _, err := repo.GetSnapshot(height); err != ErrSnapshotNotFound {
+			t.Errorf("Expected snapshot %v not to be found but got %v", height, err)
+		}
+	}
+
+	if _, err := os.Stat(repo.db(1000).Filename); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for the missing snapshot but got %v", err)
+	}
+
+	if height := repo.LatestHeight(); height != 5 {
+		t.Errorf("Expected latest height 5 but got %v", height)
+	}
+
+	if snapshot, err := repo.GetSnapshot(5); err != nil || snapshot.Height != 5 {
+		t.Errorf("Expected snapshot 5 but got %v, %v", snapshot.Height, err)
+	}
+}
@@ -0,0 +1,62 @@
+package repos
+
+import (
+	"encoding/json"
+
+	sub "github.com/antavelos/blockchain/src/internal/pkg/models/submission"
+	database "github.com/antavelos/blockchain/src/pkg/db"
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+type SubmissionRepo struct {
+	db *database.DB
+}
+
+func NewSubmissionRepo(db *database.DB) *SubmissionRepo {
+	return &SubmissionRepo{db: db}
+}
+
+func (r *SubmissionRepo) GetSubmissions() (submissions []sub.Submission, err error) {
+	data, err := r.db.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return []sub.Submission{}, nil
+	}
+
+	err = json.Unmarshal(data, &submissions)
+
+	return submissions, err
+}
+
+func (r *SubmissionRepo) GetSubmission(id string) (sub.Submission, error) {
+	submissions, err := r.GetSubmissions()
+	if err != nil {
+		return sub.Submission{}, err
+	}
+
+	index := sub.Find(submissions, id)
+	if index == -1 {
+		return sub.Submission{}, utils.GenericError{Msg: "submission not found"}
+	}
+
+	return submissions[index], nil
+}
+
+func (r *SubmissionRepo) AddSubmission(submission sub.Submission) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		submissions, _ := sub.UnmarshalMany(data)
+
+		return sub.AddSubmission(submissions, submission)
+	})
+}
+
+func (r *SubmissionRepo) UpdateSubmission(submission sub.Submission) error {
+	return r.db.WithLock(func(data []byte) (any, error) {
+		submissions, _ := sub.UnmarshalMany(data)
+
+		return sub.UpdateSubmission(submissions, submission)
+	})
+}
@@ -32,6 +32,17 @@ func (c Config) GetFloat(key string, defaultVal float64) float64 {
 	return value
 }
 
+func (c Config) GetBool(key string, defaultVal bool) bool {
+	value, err := strconv.ParseBool(c[key])
+	if err != nil {
+		msg := fmt.Sprintf("Couldn't parse '%v' config value. Using default value: %v", key, defaultVal)
+		utils.LogInfo(msg)
+		return defaultVal
+	}
+
+	return value
+}
+
 func getEnvVar(envVarKey string) (string, error) {
 	value, found := os.LookupEnv(envVarKey)
 	if !found {
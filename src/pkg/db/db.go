@@ -8,6 +8,7 @@ import (
 
 type DB struct {
 	Filename string
+	mu       sync.Mutex
 }
 
 func NewDB(filename string) *DB {
@@ -37,9 +38,8 @@ func (db *DB) Save(data any) error {
 }
 
 func (db *DB) WithLock(processData func([]byte) (any, error)) error {
-	m := sync.Mutex{}
-	m.Lock()
-	defer m.Unlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
 	data, err := db.Load()
 	if err != nil {
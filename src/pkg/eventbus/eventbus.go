@@ -2,6 +2,7 @@ package eventbus
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/antavelos/blockchain/src/pkg/utils"
 )
@@ -16,21 +17,52 @@ type DataEvent struct {
 type EventHandlers map[Event]func(DataEvent)
 
 type Bus struct {
-	handlers EventHandlers
+	handlers    EventHandlers
+	subscribers map[Event][]chan DataEvent
+	m           sync.RWMutex
 }
 
 func NewBus() *Bus {
-	return &Bus{handlers: make(EventHandlers)}
+	return &Bus{handlers: make(EventHandlers), subscribers: make(map[Event][]chan DataEvent)}
 }
 
 func (b *Bus) RegisterEventHandler(ev Event, handler func(DataEvent)) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
 	b.handlers[ev] = handler
 }
 
+// Subscribe returns a channel notified of the given event. Notifications are
+// meant as wake-up signals: while one is still pending in the channel, further
+// ones are dropped.
+func (b *Bus) Subscribe(ev Event) <-chan DataEvent {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	ch := make(chan DataEvent, 1)
+	b.subscribers[ev] = append(b.subscribers[ev], ch)
+
+	return ch
+}
+
 func (b *Bus) Handle(de DataEvent) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	for _, ch := range b.subscribers[de.Ev] {
+		select {
+		case ch <- de:
+		default:
+		}
+	}
+
 	handler, ok := b.handlers[de.Ev]
 	if !ok {
-		utils.LogError("event handler not available")
+		if len(b.subscribers[de.Ev]) == 0 {
+			utils.LogError("event handler not available")
+		}
+		return
 	}
 	utils.LogInfo(fmt.Sprintf("Handling '%v' event", de.Ev))
 	go handler(de)
@@ -0,0 +1,83 @@
+package nettime
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/antavelos/blockchain/src/pkg/utils"
+)
+
+// maxSamples is the number of peers whose clock offsets are kept.
+const maxSamples = 200
+
+// Clock is the network-adjusted time: the local time corrected by the median
+// of the offsets of the peers' clocks, the local one included. Offsets beyond
+// maxAdjustment are not applied as they point at a wrong local clock or at
+// lying peers, and are warned about instead.
+type Clock struct {
+	maxAdjustment time.Duration
+
+	m       sync.Mutex
+	offsets map[string]time.Duration
+	peers   []string
+	offset  time.Duration
+}
+
+func NewClock(maxAdjustment time.Duration) *Clock {
+	return &Clock{maxAdjustment: maxAdjustment, offsets: make(map[string]time.Duration)}
+}
+
+// AddSample records the time a peer reported, replacing the peer's former
+// sample.
+func (c *Clock) AddSample(peer string, peerTime time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if _, found := c.offsets[peer]; !found {
+		c.peers = append(c.peers, peer)
+	}
+	c.offsets[peer] = time.Until(peerTime)
+
+	if len(c.peers) > maxSamples {
+		delete(c.offsets, c.peers[0])
+		c.peers = c.peers[1:]
+	}
+
+	median := c.medianOffset()
+	if median > c.maxAdjustment || median < -c.maxAdjustment {
+		utils.LogError(fmt.Sprintf("WARNING: the local clock diverges from the network by %v. Please check the system time!", -median))
+		c.offset = 0
+		return
+	}
+
+	c.offset = median
+}
+
+func (c *Clock) medianOffset() time.Duration {
+	offsets := []time.Duration{0}
+	for _, offset := range c.offsets {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	middle := len(offsets) / 2
+	if len(offsets)%2 == 0 {
+		return (offsets[middle-1] + offsets[middle]) / 2
+	}
+
+	return offsets[middle]
+}
+
+// Offset is the adjustment applied to the local time.
+func (c *Clock) Offset() time.Duration {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.offset
+}
+
+func (c *Clock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
@@ -0,0 +1,42 @@
+package nettime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockAdjustsToTheMedianOffset(t *testing.T) {
+	clock := NewClock(time.Minute)
+
+	clock.AddSample("node1", time.Now().Add(10*time.Second))
+	clock.AddSample("node2", time.Now().Add(20*time.Second))
+
+	if offset := clock.Offset(); offset < 9*time.Second || offset > 10*time.Second {
+		t.Errorf("Expected an offset of about 10s but got %v", offset)
+	}
+}
+
+func TestClockIgnoresOffsetsBeyondTheMaxAdjustment(t *testing.T) {
+	clock := NewClock(time.Minute)
+
+	clock.AddSample("node1", time.Now().Add(time.Hour))
+	clock.AddSample("node2", time.Now().Add(time.Hour))
+
+	if offset := clock.Offset(); offset != 0 {
+		t.Errorf("Expected no offset but got %v", offset)
+	}
+}
+
+func TestClockCountsEveryPeerOnce(t *testing.T) {
+	clock := NewClock(time.Minute)
+
+	clock.AddSample("node1", time.Now().Add(-10*time.Second))
+	for i := 0; i < 5; i++ {
+		clock.AddSample("node2", time.Now().Add(30*time.Second))
+	}
+
+	// the median of -10s, 0s and 30s
+	if offset := clock.Offset(); offset < -time.Second || offset > time.Second {
+		t.Errorf("Expected no offset but got %v", offset)
+	}
+}
@@ -0,0 +1,179 @@
+package smt
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/antavelos/blockchain/src/pkg/crypto"
+)
+
+// Depth is the number of levels below the root. Keys are hashed to Depth bits
+// which give the path of their leaf.
+const Depth = 256
+
+const hashLength = Depth / 8
+
+var defaultHashes = computeDefaultHashes()
+
+// computeDefaultHashes returns the hashes of the empty subtrees, indexed by
+// their height. An empty leaf hashes to zeros.
+func computeDefaultHashes() [][]byte {
+	hashes := make([][]byte, Depth+1)
+	hashes[0] = make([]byte, hashLength)
+
+	for height := 1; height <= Depth; height++ {
+		hashes[height] = hashPair(hashes[height-1], hashes[height-1])
+	}
+
+	return hashes
+}
+
+func hashPair(left []byte, right []byte) []byte {
+	return crypto.HashData(append(append([]byte{}, left...), right...))
+}
+
+func leafHash(path []byte, value []byte) []byte {
+	return crypto.HashData(append(append([]byte{}, path...), value...))
+}
+
+func bit(path []byte, position int) int {
+	return int(path[position/8]>>(7-position%8)) & 1
+}
+
+type leaf struct {
+	path []byte
+	hash []byte
+}
+
+// Tree is a sparse Merkle tree kept in memory as its set of leaves.
+type Tree struct {
+	leaves map[string]leaf
+}
+
+func New() *Tree {
+	return &Tree{leaves: make(map[string]leaf)}
+}
+
+func path(key []byte) []byte {
+	return crypto.HashData(key)
+}
+
+func (t *Tree) Set(key []byte, value []byte) {
+	p := path(key)
+	t.leaves[string(p)] = leaf{path: p, hash: leafHash(p, value)}
+}
+
+func (t *Tree) sortedLeaves() []leaf {
+	leaves := make([]leaf, 0, len(t.leaves))
+	for _, l := range t.leaves {
+		leaves = append(leaves, l)
+	}
+
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].path, leaves[j].path) < 0
+	})
+
+	return leaves
+}
+
+// subtreeHash computes the hash of the subtree of the given height holding
+// the sorted leaves. Siblings along the target path are collected when a
+// target is given.
+func subtreeHash(height int, leaves []leaf, target []byte, siblings [][]byte) []byte {
+	if len(leaves) == 0 {
+		return defaultHashes[height]
+	}
+
+	if height == 0 {
+		return leaves[0].hash
+	}
+
+	position := Depth - height
+	split := sort.Search(len(leaves), func(i int) bool {
+		return bit(leaves[i].path, position) == 1
+	})
+
+	if target == nil {
+		left := subtreeHash(height-1, leaves[:split], nil, nil)
+		right := subtreeHash(height-1, leaves[split:], nil, nil)
+		return hashPair(left, right)
+	}
+
+	var left, right []byte
+	if bit(target, position) == 0 {
+		right = subtreeHash(height-1, leaves[split:], nil, nil)
+		siblings[height-1] = right
+		left = subtreeHash(height-1, leaves[:split], target, siblings)
+	} else {
+		left = subtreeHash(height-1, leaves[:split], nil, nil)
+		siblings[height-1] = left
+		right = subtreeHash(height-1, leaves[split:], target, siblings)
+	}
+
+	return hashPair(left, right)
+}
+
+func (t *Tree) Root() []byte {
+	return subtreeHash(Depth, t.sortedLeaves(), nil, nil)
+}
+
+// Proof holds the non default siblings of a leaf from the bottom up. Bitmap
+// marks the heights whose sibling is included.
+type Proof struct {
+	Bitmap   []byte   `json:"bitmap"`
+	Siblings [][]byte `json:"siblings"`
+}
+
+func (t *Tree) Prove(key []byte) Proof {
+	siblings := make([][]byte, Depth)
+	for height := range siblings {
+		siblings[height] = defaultHashes[height]
+	}
+
+	subtreeHash(Depth, t.sortedLeaves(), path(key), siblings)
+
+	proof := Proof{Bitmap: make([]byte, hashLength), Siblings: [][]byte{}}
+	for height, sibling := range siblings {
+		if !bytes.Equal(sibling, defaultHashes[height]) {
+			proof.Bitmap[height/8] |= 1 << (7 - height%8)
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+
+	return proof
+}
+
+// VerifyProof checks that key maps to value under root. A nil value checks
+// that the key is not part of the tree.
+func VerifyProof(root []byte, key []byte, value []byte, proof Proof) bool {
+	if len(proof.Bitmap) != hashLength {
+		return false
+	}
+
+	p := path(key)
+
+	hash := defaultHashes[0]
+	if value != nil {
+		hash = leafHash(p, value)
+	}
+
+	next := 0
+	for height := 0; height < Depth; height++ {
+		sibling := defaultHashes[height]
+		if bit(proof.Bitmap, height) == 1 {
+			if next >= len(proof.Siblings) {
+				return false
+			}
+			sibling = proof.Siblings[next]
+			next++
+		}
+
+		if bit(p, Depth-height-1) == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+	}
+
+	return next == len(proof.Siblings) && bytes.Equal(hash, root)
+}
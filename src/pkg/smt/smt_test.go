@@ -0,0 +1,51 @@
+package smt
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestProof(t *testing.T) {
+	tree := New()
+	for i := 0; i < 10; i++ {
+		tree.Set([]byte(fmt.Sprintf("key%v", i)), []byte(fmt.Sprintf("value%v", i)))
+	}
+	root := tree.Root()
+
+	for i := 0; i < 10; i++ {
+		key := []byte(fmt.Sprintf("key%v", i))
+		proof := tree.Prove(key)
+
+		if !VerifyProof(root, key, []byte(fmt.Sprintf("value%v", i)), proof) {
+			t.Errorf("Expected proof of %s to verify", key)
+		}
+
+		if VerifyProof(root, key, []byte("other"), proof) {
+			t.Errorf("Expected proof of %s with a wrong value to fail", key)
+		}
+	}
+
+	missing := []byte("missing")
+	if !VerifyProof(root, missing, nil, tree.Prove(missing)) {
+		t.Errorf("Expected non membership proof to verify")
+	}
+}
+
+func TestRootIsOrderIndependent(t *testing.T) {
+	tree1 := New()
+	tree1.Set([]byte("a"), []byte("1"))
+	tree1.Set([]byte("b"), []byte("2"))
+
+	tree2 := New()
+	tree2.Set([]byte("b"), []byte("2"))
+	tree2.Set([]byte("a"), []byte("1"))
+
+	if !bytes.Equal(tree1.Root(), tree2.Root()) {
+		t.Errorf("Expected equal roots regardless of insertion order")
+	}
+
+	if bytes.Equal(New().Root(), tree1.Root()) {
+		t.Errorf("Expected the empty tree root to differ")
+	}
+}